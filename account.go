@@ -0,0 +1,128 @@
+package gdrive
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"google.golang.org/api/drive/v3"
+)
+
+// revokeURL Google OAuth2 Token 撤銷端點
+var revokeURL = "https://oauth2.googleapis.com/revoke"
+
+// AuthStatus 當前授權狀態
+type AuthStatus struct {
+	Email           string    // 賬號郵箱
+	DisplayName     string    // 賬號顯示名稱
+	Scopes          []string  // 已授予的權限範圍（Token 未記錄時為空，不代表未授予）
+	Expiry          time.Time // Access Token 過期時間
+	HasRefreshToken bool      // 是否持有 Refresh Token
+	ServiceAccount  bool      // 是否為服務賬號模式
+}
+
+// AuthStatus 獲取當前授權狀態
+// 返回: 授權狀態和錯誤信息
+func (c *Client) AuthStatus() (*AuthStatus, error) {
+	return c.AuthStatusContext(context.Background())
+}
+
+// AuthStatusContext 獲取當前授權狀態（支持 context 取消）
+func (c *Client) AuthStatusContext(ctx context.Context) (*AuthStatus, error) {
+	token, err := c.session.token(ctx)
+	if err != nil {
+		return nil, c.config.newError(CodeGetTokenFailed, err)
+	}
+
+	var about *drive.About
+	err = c.retry(ctx, CodeAboutFailed, func() (err error) {
+		about, err = c.service.About.Get().
+			Fields("user(emailAddress, displayName)").
+			Context(ctx).
+			Do()
+		return err
+	})
+	if err != nil {
+		return nil, c.config.newError(CodeAboutFailed, err)
+	}
+
+	status := &AuthStatus{
+		Scopes:          tokenScopes(token),
+		Expiry:          token.Expiry,
+		HasRefreshToken: token.RefreshToken != "",
+		ServiceAccount:  c.session.serviceAccount,
+	}
+	if about.User != nil {
+		status.Email = about.User.EmailAddress
+		status.DisplayName = about.User.DisplayName
+	}
+
+	return status, nil
+}
+
+// Logout 撤銷當前 Token 並從 Token 存儲中刪除
+// 調用後客戶端不可再使用，需要重新創建客戶端並授權
+func (c *Client) Logout() error {
+	return c.LogoutContext(context.Background())
+}
+
+// LogoutContext 撤銷當前 Token 並從 Token 存儲中刪除（支持 context 取消）
+func (c *Client) LogoutContext(ctx context.Context) error {
+	if c.session.store == nil {
+		return c.config.newError(CodeLogoutUnsupported, nil)
+	}
+
+	c.StopBackup()
+
+	// 優先撤銷 Refresh Token（同時使其派生的 Access Token 失效）
+	var revokeErr error
+	token, err := c.session.store.Load()
+	if err == nil {
+		value := token.RefreshToken
+		if value == "" {
+			value = token.AccessToken
+		}
+		revokeErr = revokeToken(ctx, c.session.base, value, c.config.language())
+	}
+
+	// 無論撤銷是否成功，都刪除本地 Token
+	if err := c.session.store.Delete(); err != nil {
+		return c.config.newError(CodeDeleteTokenFailed, err)
+	}
+	c.session.loggedOut()
+
+	if revokeErr != nil {
+		return c.config.newError(CodeRevokeTokenFailed, revokeErr)
+	}
+	return nil
+}
+
+// revokeToken 調用 Google 撤銷端點撤銷 Token
+// language: 錯誤信息語言
+func revokeToken(ctx context.Context, httpClient *http.Client, token string, language Language) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, revokeURL, strings.NewReader(url.Values{"token": {token}}.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusOK {
+		return nil
+	}
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	// Token 已失效或已被撤銷，視為成功
+	if resp.StatusCode == http.StatusBadRequest && strings.Contains(string(body), "invalid_token") {
+		return nil
+	}
+	return language.newError(CodeRevokeRejected, nil, resp.StatusCode, strings.TrimSpace(string(body)))
+}
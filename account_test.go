@@ -0,0 +1,31 @@
+package gdrive_test
+
+import (
+	"testing"
+
+	"github.com/Digman/gdrive"
+	"github.com/Digman/gdrive/gdrivetest"
+)
+
+func TestAuthStatusDoesNotReportUnverifiedScopes(t *testing.T) {
+	srv := gdrivetest.NewServer()
+	defer srv.Close()
+	srv.SetUser(gdrivetest.User{DisplayName: "Test", EmailAddress: "test@example.com"})
+
+	// 模擬服務的 TokenSource 返回的 Token 沒有記錄權限範圍
+	client, err := srv.NewClient(&gdrive.Config{FolderName: "backups", Scopes: []string{"drive"}, Logger: testLogger{t}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	status, err := client.AuthStatus()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(status.Scopes) != 0 {
+		t.Fatalf("Scopes = %v, want empty", status.Scopes)
+	}
+	if status.Email != "test@example.com" || status.DisplayName != "Test" {
+		t.Fatalf("AuthStatus = %+v", status)
+	}
+}
@@ -3,20 +3,26 @@ package gdrive
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"runtime"
+	"strings"
 	"time"
 
 	"golang.org/x/oauth2"
 	"golang.org/x/oauth2/google"
-	"google.golang.org/api/drive/v3"
 )
 
+// serviceAccountType 服務賬號密鑰文件的 type 字段值
+const serviceAccountType = "service_account"
+
 // credentialsFile 憑據文件結構
 type credentialsFile struct {
+	Type      string `json:"type"` // 服務賬號密鑰為 "service_account"
 	Installed *struct {
 		ClientID     string   `json:"client_id"`
 		ClientSecret string   `json:"client_secret"`
@@ -27,162 +33,277 @@ type credentialsFile struct {
 }
 
 // showCredentialsSetupGuide 顯示憑據設置指南並打開瀏覽器
-func showCredentialsSetupGuide(credentialsPath string) {
-	fmt.Println()
-	fmt.Println("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
-	fmt.Println("  ⚠️  未找到或無法解析憑據文件")
-	fmt.Println("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
-	fmt.Println()
-	fmt.Printf("  預期路徑: %s\n", credentialsPath)
-	fmt.Println()
-	fmt.Println("  📝 請按照以下步驟獲取憑據文件：")
-	fmt.Println()
-	fmt.Println("  1. 訪問 Google Cloud Console（瀏覽器將自動打開）")
-	fmt.Println("  2. 創建或選擇項目")
-	fmt.Println("  3. 啟用 Google Drive API")
-	fmt.Println("  4. 創建 OAuth2 憑據（類型：電視和受限輸入設備）")
-	fmt.Println("  5. 下載憑據文件並保存為上述路徑")
-	fmt.Println()
-	fmt.Println("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
-	fmt.Println()
-
-	// 打開 Google Cloud Console
-	consoleURL := "https://console.cloud.google.com/apis/credentials"
-	if err := openBrowser(consoleURL); err != nil {
-		fmt.Printf("  提示：無法自動打開瀏覽器，請手動訪問：\n  %s\n\n", consoleURL)
-	} else {
-		fmt.Println("  ✓ 已在瀏覽器中打開 Google Cloud Console")
-		fmt.Println()
-	}
+func showCredentialsSetupGuide(config *Config) {
+	config.prompter().PromptCredentialsSetup(CredentialsGuide{
+		CredentialsPath: config.CredentialsFile,
+		ConsoleURL:      credentialsConsoleURL,
+		BrowserOpened:   config.launchBrowser(credentialsConsoleURL),
+	})
 }
 
 // getOAuth2Client 獲取已認證的 OAuth2 HTTP 客戶端
-func getOAuth2Client(config *Config) (*http.Client, error) {
-	ctx := context.Background()
+// ctx 用於控制交互式授權流程（如 Device Flow 輪詢），取消後授權立即中止
+// base 為底層 HTTP 客戶端，所有授權請求都通過它發出
+func getOAuth2Client(ctx context.Context, config *Config, base *http.Client) (*authSession, error) {
+	ctx = withHTTPClient(ctx, base)
 
 	// 讀取憑據文件
 	credentialsData, err := os.ReadFile(config.CredentialsFile)
 	if err != nil {
-		showCredentialsSetupGuide(config.CredentialsFile)
-		return nil, fmt.Errorf("無法讀取憑據文件: %w", err)
+		showCredentialsSetupGuide(config)
+		return nil, config.newError(CodeReadCredentials, err)
 	}
 
 	// 解析憑據文件
 	var creds credentialsFile
 	if err := json.Unmarshal(credentialsData, &creds); err != nil {
-		showCredentialsSetupGuide(config.CredentialsFile)
-		return nil, fmt.Errorf("無法解析憑據文件: %w", err)
+		showCredentialsSetupGuide(config)
+		return nil, config.newError(CodeParseCredentials, err)
+	}
+
+	// 根據憑據類型選擇認證模式
+	switch {
+	case creds.Type == serviceAccountType:
+		return getServiceAccountClient(config, credentialsData, base)
+	case creds.Installed == nil:
+		showCredentialsSetupGuide(config)
+		return nil, config.newError(CodeInvalidCredentials, nil)
 	}
 
-	if creds.Installed == nil {
-		showCredentialsSetupGuide(config.CredentialsFile)
-		return nil, fmt.Errorf("憑據文件格式錯誤：請使用「電視和受限輸入設備」或「已安裝應用」類型的 OAuth2 客戶端")
+	// 用戶授權模式需要保存 Token
+	if config.TokenFile == "" && config.TokenStore == nil {
+		return nil, config.newError(CodeMissingTokenFile, nil)
 	}
 
-	// 手動構建 OAuth2 配置（Device Flow）
+	// 手動構建 OAuth2 配置
 	// 注意：Device Flow 不支持某些敏感權限範圍
-	// 使用 drive.file 範圍，允許訪問應用創建和打開的文件
+	// 默認使用 drive.file 範圍，允許訪問應用創建和打開的文件
+	scopes := config.scopes()
+	endpoint := google.Endpoint
+	if creds.Installed.AuthURI != "" {
+		endpoint.AuthURL = creds.Installed.AuthURI
+	}
+	if creds.Installed.TokenURI != "" {
+		endpoint.TokenURL = creds.Installed.TokenURI
+	}
 	oauthConfig := &oauth2.Config{
 		ClientID:     creds.Installed.ClientID,
 		ClientSecret: creds.Installed.ClientSecret,
-		Endpoint:     google.Endpoint,
-		Scopes:       []string{drive.DriveFileScope},
+		Endpoint:     endpoint,
+		Scopes:       scopes,
 	}
 
-	// 嘗試從文件加載 Token
-	token, err := loadToken(config.TokenFile)
+	// 嘗試從 Token 存儲加載 Token
+	store := config.tokenStore()
+	token, err := loadValidToken(config, store)
+	if err == nil {
+		// 已保存的 Token 缺少所需權限範圍時重新授權
+		if missing := missingScopes(token, scopes); len(missing) > 0 {
+			config.logger().Warningf(config.text(msgScopesMissing), strings.Join(missing, ", "))
+			err = config.newError(CodeInsufficientScope, nil)
+		}
+	}
+	if err != nil && !needsAuthorization(err) {
+		// 口令錯誤、密鑰缺失、文件損壞等問題重新授權也無法解決，且會覆蓋原有 Token
+		if ErrorCodeOf(err) == "" {
+			err = config.newError(CodeLoadTokenFailed, err)
+		}
+		return nil, err
+	}
 	if err != nil {
-		// Token 不存在或無效，需要重新認證
-		token, err = getTokenFromDeviceFlow(ctx, oauthConfig)
+		// Token 不存在、已過期或權限不足，需要重新認證
+		token, err = authorize(ctx, config, oauthConfig)
 		if err != nil {
-			return nil, fmt.Errorf("設備認證失敗: %w", err)
+			return nil, err
 		}
 
 		// 保存 Token
-		if err := saveToken(config.TokenFile, token); err != nil {
-			return nil, fmt.Errorf("保存 Token 失敗: %w", err)
+		if err := store.Save(token); err != nil {
+			return nil, config.newError(CodeSaveTokenFailed, err)
+		}
+	}
+
+	// 創建認證會話（自動處理 Token 刷新，並將刷新後的 Token 寫回存儲）
+	return newUserSession(config, oauthConfig, store, token, base), nil
+}
+
+// getServiceAccountClient 使用服務賬號密鑰創建 HTTP 客戶端（JWT 授權，無需用戶交互）
+// 配置了 ImpersonateSubject 時通過域範圍委派模擬該用戶
+func getServiceAccountClient(config *Config, credentialsData []byte, base *http.Client) (*authSession, error) {
+	scopes := config.scopes()
+	jwtConfig, err := google.JWTConfigFromJSON(credentialsData, scopes...)
+	if err != nil {
+		return nil, config.newError(CodeParseServiceAccount, err)
+	}
+	jwtConfig.Subject = config.ImpersonateSubject
+
+	// Token 在客戶端整個生命週期內刷新，不綁定創建時的 ctx
+	tokenSource := jwtConfig.TokenSource(withHTTPClient(context.Background(), base))
+	return newStaticSession(config, tokenSource, scopes, base, true), nil
+}
+
+// authorize 按配置的授權流程獲取新 Token
+func authorize(ctx context.Context, config *Config, oauthConfig *oauth2.Config) (*oauth2.Token, error) {
+	if config.AuthFlow == AuthFlowLoopback {
+		token, err := getTokenFromLoopbackFlow(ctx, config, oauthConfig)
+		if err != nil {
+			return nil, config.newError(CodeLoopbackAuthFailed, err)
 		}
+		return token, nil
 	}
 
-	// 創建 HTTP 客戶端（自動處理 Token 刷新）
-	return oauthConfig.Client(ctx, token), nil
+	token, err := getTokenFromDeviceFlow(ctx, config, oauthConfig)
+	if err != nil {
+		return nil, config.newError(CodeDeviceAuthFailed, err)
+	}
+	return token, nil
 }
 
 // getTokenFromDeviceFlow 通過 Device Flow 獲取新 Token
-func getTokenFromDeviceFlow(ctx context.Context, oauthConfig *oauth2.Config) (*oauth2.Token, error) {
+func getTokenFromDeviceFlow(ctx context.Context, config *Config, oauthConfig *oauth2.Config) (*oauth2.Token, error) {
 	// 獲取設備代碼
 	deviceAuthResp, err := oauthConfig.DeviceAuth(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("無法獲取設備代碼: %w", err)
+		return nil, config.newError(CodeDeviceCodeFailed, checkDeviceScopeError(config, err, oauthConfig.Scopes))
 	}
 
-	// 顯示用戶授權信息
-	fmt.Println()
-	fmt.Println("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
-	fmt.Println("  🔐 Google Drive 設備授權")
-	fmt.Println("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
-	fmt.Println()
-	fmt.Println("  1. 瀏覽器將自動打開授權頁面")
-	fmt.Printf("  2. 網址：%s\n", deviceAuthResp.VerificationURI)
-	fmt.Printf("  3. 輸入授權碼：\033[1;36m%s\033[0m\n", deviceAuthResp.UserCode)
-	fmt.Println()
-	fmt.Println("  ⏳ 等待授權...")
-	fmt.Println()
-
-	// 嘗試打開瀏覽器
-	if err := openBrowser(deviceAuthResp.VerificationURI); err != nil {
-		fmt.Printf("  ⚠️  無法自動打開瀏覽器，請手動訪問上方網址\n\n")
+	// 嘗試打開瀏覽器並顯示用戶授權信息
+	prompter := config.prompter()
+	code := DeviceCode{
+		VerificationURI:         deviceAuthResp.VerificationURI,
+		VerificationURIComplete: deviceAuthResp.VerificationURIComplete,
+		UserCode:                deviceAuthResp.UserCode,
+		Expiry:                  deviceAuthResp.Expiry,
 	}
+	code.BrowserOpened = config.launchBrowser(deviceAuthResp.VerificationURI)
+	prompter.PromptDeviceCode(code)
 
 	// 輪詢等待用戶授權
 	token, err := oauthConfig.DeviceAccessToken(ctx, deviceAuthResp)
 	if err != nil {
-		return nil, fmt.Errorf("等待授權超時或失敗: %w", err)
+		return nil, config.newError(CodeAuthTimeout, err)
 	}
 
-	fmt.Println("  ✅ Google Drive 設備授權授權成功！")
-	fmt.Println()
+	prompter.DeviceAuthorized()
 	return token, nil
 }
 
+// storedToken Token 文件結構（在 oauth2.Token 基礎上記錄已授予的權限範圍）
+type storedToken struct {
+	oauth2.Token
+	Scope string `json:"scope,omitempty"`
+}
+
 // saveToken 保存 Token 到文件
-func saveToken(path string, token *oauth2.Token) error {
-	file, err := os.Create(path)
+// encryption 不為 nil 時以 AES-GCM 加密後保存；文件權限為 0600，通過臨時文件加重命名原子寫入
+// language: 錯誤信息語言
+func saveToken(path string, token *oauth2.Token, encryption *TokenEncryption, language Language) error {
+	data, err := json.MarshalIndent(storedToken{Token: *token, Scope: strings.Join(tokenScopes(token), " ")}, "", "  ")
 	if err != nil {
-		return fmt.Errorf("無法創建 Token 文件: %w", err)
+		return language.newError(CodeWriteTokenFile, err)
 	}
 
-	encoder := json.NewEncoder(file)
-	encoder.SetIndent("", "  ")
-	if err := encoder.Encode(token); err != nil {
+	if encryption != nil {
+		envelope, err := encryption.encrypt(data, language)
+		if err != nil {
+			return language.newError(CodeEncryptTokenFailed, err)
+		}
+		if data, err = json.MarshalIndent(envelope, "", "  "); err != nil {
+			return language.newError(CodeWriteTokenFile, err)
+		}
+	}
+
+	// 在同一目錄創建臨時文件（os.CreateTemp 創建的文件權限為 0600）
+	file, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return language.newError(CodeWriteTokenFile, err)
+	}
+	tempPath := file.Name()
+
+	if _, err := file.Write(append(data, '\n')); err != nil {
 		_ = file.Close() // 忽略關閉錯誤，因為寫入已失敗
-		return fmt.Errorf("無法寫入 Token: %w", err)
+		_ = os.Remove(tempPath)
+		return language.newError(CodeWriteTokenFile, err)
+	}
+
+	if err := file.Sync(); err != nil {
+		_ = file.Close()
+		_ = os.Remove(tempPath)
+		return language.newError(CodeWriteTokenFile, err)
 	}
 
 	// 明確檢查 Close 錯誤
 	if err := file.Close(); err != nil {
-		return fmt.Errorf("無法關閉 Token 文件: %w", err)
+		_ = os.Remove(tempPath)
+		return language.newError(CodeWriteTokenFile, err)
+	}
+
+	// 原子替換舊文件
+	if err := os.Rename(tempPath, path); err != nil {
+		_ = os.Remove(tempPath)
+		return language.newError(CodeWriteTokenFile, err)
 	}
 
 	return nil
 }
 
-// loadToken 從文件加載 Token
-func loadToken(path string) (*oauth2.Token, error) {
-	file, err := os.Open(path)
+// loadToken 從文件加載 Token（自動識別並解密加密格式）
+// language: 錯誤信息語言
+func loadToken(path string, encryption *TokenEncryption, language Language) (*oauth2.Token, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, language.newError(CodeTokenNotFound, err)
+	}
 	if err != nil {
-		return nil, err
+		return nil, language.newError(CodeLoadTokenFailed, err)
 	}
-	defer file.Close() // 讀取操作可以忽略 Close 錯誤
 
-	token := &oauth2.Token{}
-	if err := json.NewDecoder(file).Decode(token); err != nil {
-		return nil, fmt.Errorf("無法解析 Token 文件: %w", err)
+	// 檢查是否為加密格式
+	var envelope tokenEnvelope
+	if err := json.Unmarshal(data, &envelope); err != nil {
+		return nil, language.newError(CodeParseTokenFile, err)
+	}
+
+	if envelope.Format != "" {
+		if encryption == nil {
+			return nil, language.newError(CodeTokenEncrypted, nil)
+		}
+		if data, err = encryption.decrypt(&envelope, language); err != nil {
+			return nil, err
+		}
+	}
+
+	var stored storedToken
+	if err := json.Unmarshal(data, &stored); err != nil {
+		return nil, language.newError(CodeParseTokenFile, err)
+	}
+
+	token := &stored.Token
+	if stored.Scope != "" {
+		token = withScopes(token, strings.Fields(stored.Scope))
+	}
+	return token, nil
+}
+
+// needsAuthorization 判斷加載 Token 的錯誤是否應通過重新授權解決（Token 不存在、已過期或缺少權限範圍）
+func needsAuthorization(err error) bool {
+	switch ErrorCodeOf(err) {
+	case CodeTokenExpired, CodeInsufficientScope:
+		return true
+	}
+	return errors.Is(err, ErrNotFound) || errors.Is(err, os.ErrNotExist)
+}
+
+// loadValidToken 從 Token 存儲加載 Token 並檢查是否仍可使用
+func loadValidToken(config *Config, store TokenStore) (*oauth2.Token, error) {
+	token, err := store.Load()
+	if err != nil {
+		return nil, err
 	}
 
 	// 檢查 Token 是否過期
 	if token.Expiry.Before(time.Now()) && token.RefreshToken == "" {
-		return nil, fmt.Errorf("token 已過期且無法刷新")
+		return nil, config.newError(CodeTokenExpired, nil)
 	}
 
 	return token, nil
@@ -200,7 +321,7 @@ func openBrowser(url string) error {
 	case "windows":
 		cmd = exec.Command("rundll32", "url.dll,FileProtocolHandler", url)
 	default:
-		return fmt.Errorf("不支持的操作系統: %s", runtime.GOOS)
+		return fmt.Errorf("%w: %s", errors.ErrUnsupported, runtime.GOOS)
 	}
 
 	return cmd.Start()
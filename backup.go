@@ -1,9 +1,13 @@
 package gdrive
 
 import (
+	"context"
 	"os"
 	"path/filepath"
+	"sync"
 	"time"
+
+	"google.golang.org/api/drive/v3"
 )
 
 // BackupScheduler 備份調度器
@@ -12,79 +16,106 @@ type BackupScheduler struct {
 	client          *Client
 	ticker          *time.Ticker
 	stopChan        chan struct{}
+	stopOnce        sync.Once
+	done            chan struct{}        // 調度 goroutine 退出時關閉
+	cancel          context.CancelFunc   // 取消正在進行的備份
 	lastBackupTimes map[string]time.Time // 記錄每個文件的上次備份時間
 	logger          Logger               // 日志實例
 }
 
 // NewBackupScheduler 創建備份調度器
 func NewBackupScheduler(config *Config, client *Client) *BackupScheduler {
-	logger := config.Logger
-	if logger == nil {
-		logger = newDefaultLogger()
-	}
-
 	return &BackupScheduler{
 		config:          config,
 		client:          client,
 		lastBackupTimes: make(map[string]time.Time),
-		logger:          logger,
+		logger:          config.logger(),
 	}
 }
 
 // Start 啟動調度器（異步運行）
 func (s *BackupScheduler) Start() {
+	s.StartContext(context.Background())
+}
+
+// StartContext 啟動調度器（異步運行），ctx 取消時停止調度並中止正在進行的上傳
+func (s *BackupScheduler) StartContext(ctx context.Context) {
+	ctx, s.cancel = context.WithCancel(ctx)
 	s.ticker = time.NewTicker(s.config.BackupInterval)
 	s.stopChan = make(chan struct{})
+	s.done = make(chan struct{})
 
 	// 異步執行定時任務
 	go func() {
+		defer close(s.done)
+		defer s.ticker.Stop()
+
 		// 啟動時立即執行一次
-		s.runBackup()
+		s.runBackup(ctx)
 
 		for {
 			select {
 			case <-s.ticker.C:
-				s.runBackup()
+				s.runBackup(ctx)
 			case <-s.stopChan:
-				s.ticker.Stop()
+				return
+			case <-ctx.Done():
 				return
 			}
 		}
 	}()
 
-	s.logger.Infof("✅ 定時備份已啟動，間隔: %v", s.config.BackupInterval)
+	s.logger.Infof(s.config.text(msgBackupStarted), s.config.BackupInterval)
 }
 
-// Stop 停止調度器
+// Stop 停止調度器（可重複調用）
 func (s *BackupScheduler) Stop() {
-	if s.stopChan != nil {
-		close(s.stopChan)
-		s.logger.Infof("✅ 定時備份已停止")
+	if s.stopChan == nil {
+		return
 	}
+	s.stopOnce.Do(func() {
+		s.cancel()
+		close(s.stopChan)
+		s.logger.Infof(s.config.text(msgBackupStopped))
+	})
+}
+
+// Done 返回調度 goroutine 退出時關閉的 channel（調用 Stop 或 ctx 取消後），未啟動時返回 nil
+func (s *BackupScheduler) Done() <-chan struct{} {
+	return s.done
 }
 
 // runBackup 執行一次備份任務
-func (s *BackupScheduler) runBackup() {
-	s.logger.Infof("🔄 開始備份任務...")
+func (s *BackupScheduler) runBackup(ctx context.Context) {
+	// 授權失效時跳過本次備份，避免每個文件都失敗；正在重新授權時等待完成
+	if err := s.client.session.waitReady(ctx); err != nil {
+		s.logger.Errorf(s.config.text(msgBackupSkipped), err)
+		return
+	}
+
+	s.logger.Infof(s.config.text(msgBackupRunning))
 
 	files, err := s.scanFiles()
 	if err != nil {
-		s.logger.Errorf("❌ 掃描文件失敗: %v", err)
+		s.logger.Errorf(s.config.text(msgBackupScanFailed), err)
 		return
 	}
 
 	if len(files) == 0 {
-		s.logger.Infof("ℹ️  沒有文件需要備份")
+		s.logger.Infof(s.config.text(msgBackupNothing))
 		return
 	}
 
 	successCount := 0
+	unchangedCount := 0
 	failCount := 0
 
+	// 篩選需要備份的文件
+	var planned []plannedFile
 	for _, file := range files {
 		fileInfo, err := os.Stat(file)
 		if err != nil {
-			s.logger.Warningf("⚠️  訪問文件失敗 %s: %v", file, err)
+			s.logger.Warningf(s.config.text(msgBackupStatFailed), file, err)
 			failCount++
 			continue // 單個文件失敗不影響其他
 		}
@@ -94,26 +125,132 @@ func (s *BackupScheduler) runBackup() {
 			continue
 		}
 
+		// 調度器已停止時中止本次備份
+		if ctx.Err() != nil {
+			s.logger.Warningf(s.config.text(msgBackupCanceled))
+			return
+		}
+
+		// 內容與遠程文件相同的文件不上傳，也不計入存儲空間檢查和備份進度
+		existing, unchanged, err := s.client.findRemote(ctx, file)
+		if err != nil {
+			s.logger.Errorf(s.config.text(msgBackupFileFailed), file, err)
+			failCount++
+			continue // 單個文件失敗不影響其他
+		}
+		if unchanged {
+			s.lastBackupTimes[file] = fileInfo.ModTime()
+			unchangedCount++
+			s.logger.Infof(s.config.text(msgBackupUnchanged), file)
+			continue
+		}
+		planned = append(planned, plannedFile{path: file, info: fileInfo, remote: existing})
+	}
+
+	// 檢查存儲空間，按策略推遲放不下的文件
+	planned, deferred := s.checkQuota(ctx, planned)
+	progress := s.config.newRunProgress(planned, deferred)
+	// 開始上傳前報告一次，沒有需要上傳的文件時調用方也能得到本次的計劃和推遲的文件
+	progress.report(true)
+
+	for _, p := range planned {
+		file, fileInfo := p.path, p.info
+
+		// 調度器已停止時中止本次備份
+		if ctx.Err() != nil {
+			s.logger.Warningf(s.config.text(msgBackupCanceled))
+			break
+		}
+
+		// 授權失效且無法恢復時中止本次備份，剩餘文件留到下次
+		if err := s.client.session.waitReady(ctx); err != nil {
+			s.logger.Errorf(s.config.text(msgBackupAborted), err)
+			break
+		}
+
 		// 執行上傳
-		_, isNew, err := s.client.UploadOrUpdateFile(file)
+		progress.startFile(file)
+		_, status, err := s.client.uploadChanged(ctx, file, p.remote, progress.fileProgress())
+		progress.finishFile(fileInfo.Size())
 		if err != nil {
-			s.logger.Errorf("❌ 備份失敗 %s: %v", file, err)
+			s.logger.Errorf(s.config.text(msgBackupFileFailed), file, err)
 			failCount++
 			continue // 單個文件失敗不影響其他
 		}
 
 		// 記錄備份時間
 		s.lastBackupTimes[file] = fileInfo.ModTime()
-		successCount++
 
-		if isNew {
-			s.logger.Infof("✅ 已創建: %s", file)
+		switch status {
+		case UploadCreated:
+			successCount++
+			s.logger.Infof(s.config.text(msgBackupCreated), file)
+		case UploadUpdated:
+			successCount++
+			s.logger.Infof(s.config.text(msgBackupUpdated), file)
+		}
+	}
+
+	s.logger.Infof(s.config.text(msgBackupSummary), successCount, unchangedCount, failCount)
+}
+
+// plannedFile 本次計劃備份的文件
+type plannedFile struct {
+	path   string
+	info   os.FileInfo
+	remote *drive.File // 同名的遠程文件（內容不同），不存在時為 nil
+}
+
+// checkQuota 檢查計劃上傳量是否超出剩餘空間
+// QuotaPolicyWarn 時僅輸出警告；QuotaPolicyDefer 時按順序保留放得下的文件，其餘推遲到下次備份（不記錄備份時間）
+// 返回: 本次實際上傳的文件和被推遲的文件
+func (s *BackupScheduler) checkQuota(ctx context.Context, planned []plannedFile) ([]plannedFile, []plannedFile) {
+	// 共享雲端硬碟的空間不計入用戶配額，不做檢查
+	policy := s.config.quotaPolicy()
+	if policy == QuotaPolicyNone || len(planned) == 0 || s.client.driveID != "" {
+		return planned, nil
+	}
+
+	var required int64
+	for _, p := range planned {
+		required += p.info.Size()
+	}
+
+	quota, err := s.client.QuotaContext(ctx)
+	if err != nil {
+		// 無法獲取配額時不阻止備份
+		s.logger.Warningf(s.config.text(msgQuotaCheckFailed), err)
+		return planned, nil
+	}
+
+	remaining := quota.Remaining()
+	if quota.Unlimited || required <= remaining {
+		return planned, nil
+	}
+
+	// 僅明確配置 QuotaPolicyDefer 時推遲文件
+	if policy != QuotaPolicyDefer {
+		s.logger.Warningf(s.config.text(msgQuotaWarning), formatBytes(required), formatBytes(remaining))
+		return planned, nil
+	}
+
+	// 推遲放不下的文件（較小的文件仍可能放得下，繼續嘗試後續文件）
+	var kept, deferred []plannedFile
+	available := remaining
+	for _, p := range planned {
+		if p.info.Size() <= available {
+			kept = append(kept, p)
+			available -= p.info.Size()
 		} else {
-			s.logger.Infof("✅ 已更新: %s", file)
+			deferred = append(deferred, p)
 		}
 	}
 
-	s.logger.Infof("📊 備份完成 - 成功: %d, 失敗: %d", successCount, failCount)
+	s.logger.Warningf(s.config.text(msgQuotaDeferred), formatBytes(required), formatBytes(remaining), len(deferred))
+	for _, p := range deferred {
+		s.logger.Warningf(s.config.text(msgQuotaDeferredFile), p.path, formatBytes(p.info.Size()))
+	}
+	return kept, deferred
 }
 
 // scanFiles 掃描需要備份的文件列表
@@ -123,7 +260,7 @@ func (s *BackupScheduler) scanFiles() ([]string, error) {
 	for _, path := range s.config.BackupPaths {
 		fileInfo, err := os.Stat(path)
 		if err != nil {
-			s.logger.Warningf("⚠️  訪問路徑失敗 %s: %v", path, err)
+			s.logger.Warningf(s.config.text(msgBackupPathFailed), path, err)
 			continue // 單個路徑失敗不影響其他
 		}
 
@@ -148,7 +285,7 @@ func (s *BackupScheduler) scanFiles() ([]string, error) {
 				return nil
 			})
 			if err != nil {
-				s.logger.Warningf("⚠️  掃描目錄失敗 %s: %v", path, err)
+				s.logger.Warningf(s.config.text(msgBackupWalkFailed), path, err)
 			}
 		} else {
 			// 是文件：直接添加
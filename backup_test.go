@@ -0,0 +1,195 @@
+package gdrive_test
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/Digman/gdrive"
+	"github.com/Digman/gdrive/gdrivetest"
+)
+
+func TestStartBackupAfterContextCanceled(t *testing.T) {
+	srv := gdrivetest.NewServer()
+	defer srv.Close()
+
+	client, err := srv.NewClient(&gdrive.Config{
+		FolderName:     "backups",
+		BackupEnabled:  true,
+		BackupInterval: time.Hour,
+		BackupPaths:    []string{t.TempDir()},
+		Logger:         testLogger{t},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	if err := client.StartBackupContext(ctx); err != nil {
+		t.Fatalf("StartBackupContext: %v", err)
+	}
+	if err := client.StartBackup(); gdrive.ErrorCodeOf(err) != gdrive.CodeBackupRunning {
+		t.Fatalf("重複啟動 = %v, want %s", err, gdrive.CodeBackupRunning)
+	}
+
+	// ctx 取消後調度器退出，應可再次啟動
+	cancel()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		err := client.StartBackup()
+		if err == nil {
+			break
+		}
+		if gdrive.ErrorCodeOf(err) != gdrive.CodeBackupRunning || time.Now().After(deadline) {
+			t.Fatalf("ctx 取消後 StartBackup = %v", err)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	client.StopBackup()
+	client.StopBackup() // 重複停止不應 panic
+}
+
+// runBackupOnce 啟動定時備份並等待第一次備份的所有計劃文件處理完成，返回最後一次進度
+func runBackupOnce(t *testing.T, srv *gdrivetest.Server, config *gdrive.Config) gdrive.BackupProgress {
+	t.Helper()
+
+	done := make(chan gdrive.BackupProgress, 1)
+	config.BackupEnabled = true
+	config.BackupInterval = time.Hour
+	config.Logger = testLogger{t}
+	config.OnBackupProgress = func(p gdrive.BackupProgress) {
+		if p.FilesDone == p.FilesTotal {
+			select {
+			case done <- p:
+			default:
+			}
+		}
+	}
+
+	client, err := srv.NewClient(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := client.StartBackup(); err != nil {
+		t.Fatalf("StartBackup: %v", err)
+	}
+	defer client.StopBackup()
+
+	select {
+	case p := <-done:
+		return p
+	case <-time.After(10 * time.Second):
+		t.Fatal("備份未完成")
+		return gdrive.BackupProgress{}
+	}
+}
+
+// writeBackupFiles 在臨時目錄中創建備份文件，返回目錄
+func writeBackupFiles(t *testing.T, files map[string]int) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	for name, size := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), bytes.Repeat([]byte("x"), size), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestInvalidQuotaPolicy(t *testing.T) {
+	srv := gdrivetest.NewServer()
+	defer srv.Close()
+
+	// 未啟用定時備份時同樣驗證
+	_, err := srv.NewClient(&gdrive.Config{
+		FolderName:        "backups",
+		BackupQuotaPolicy: "skip",
+	})
+	if gdrive.ErrorCodeOf(err) != gdrive.CodeInvalidQuotaPolicy {
+		t.Fatalf("NewClient = %v, want %s", err, gdrive.CodeInvalidQuotaPolicy)
+	}
+}
+
+func TestBackupQuotaDefer(t *testing.T) {
+	srv := gdrivetest.NewServer()
+	defer srv.Close()
+	srv.SetQuota(1000)
+
+	// 按掃描順序：a 放得下，b 超出剩餘空間被推遲，c 仍放得下
+	dir := writeBackupFiles(t, map[string]int{"a.bin": 800, "b.bin": 400, "c.bin": 100})
+	progress := runBackupOnce(t, srv, &gdrive.Config{
+		FolderName:        "backups",
+		BackupPaths:       []string{dir},
+		BackupQuotaPolicy: gdrive.QuotaPolicyDefer,
+	})
+
+	if want := []string{filepath.Join(dir, "b.bin")}; !slices.Equal(progress.Deferred, want) {
+		t.Fatalf("Deferred = %v, want %v", progress.Deferred, want)
+	}
+	if progress.FilesTotal != 2 || progress.BytesTotal != 900 {
+		t.Fatalf("FilesTotal = %d, BytesTotal = %d, want 2, 900", progress.FilesTotal, progress.BytesTotal)
+	}
+	if len(srv.FindByName("b.bin")) != 0 {
+		t.Fatal("被推遲的文件不應上傳")
+	}
+}
+
+func TestBackupQuotaWarn(t *testing.T) {
+	srv := gdrivetest.NewServer()
+	defer srv.Close()
+	srv.SetQuota(1000)
+
+	dir := writeBackupFiles(t, map[string]int{"a.bin": 800, "b.bin": 400})
+	progress := runBackupOnce(t, srv, &gdrive.Config{
+		FolderName:  "backups",
+		BackupPaths: []string{dir},
+	})
+
+	// 默認策略只警告，仍嘗試上傳所有文件
+	if len(progress.Deferred) != 0 || progress.FilesTotal != 2 {
+		t.Fatalf("Deferred = %v, FilesTotal = %d, want none, 2", progress.Deferred, progress.FilesTotal)
+	}
+}
+
+func TestBackupQuotaSkipsUnchangedFiles(t *testing.T) {
+	srv := gdrivetest.NewServer()
+	defer srv.Close()
+
+	dir := writeBackupFiles(t, map[string]int{"a.bin": 800, "b.bin": 100})
+	client, err := srv.NewClient(&gdrive.Config{FolderName: "backups", Logger: testLogger{t}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.UploadFile(filepath.Join(dir, "a.bin")); err != nil {
+		t.Fatalf("UploadFile: %v", err)
+	}
+
+	// 剩餘 200 字節：a 內容未變不計入上傳量，b 放得下
+	srv.SetQuota(1000)
+	srv.ResetRequests()
+	progress := runBackupOnce(t, srv, &gdrive.Config{
+		FolderName:        "backups",
+		BackupPaths:       []string{dir},
+		BackupQuotaPolicy: gdrive.QuotaPolicyDefer,
+	})
+
+	if len(progress.Deferred) != 0 {
+		t.Fatalf("Deferred = %v, want none", progress.Deferred)
+	}
+	if progress.FilesTotal != 1 || progress.BytesTotal != 100 {
+		t.Fatalf("FilesTotal = %d, BytesTotal = %d, want 1, 100", progress.FilesTotal, progress.BytesTotal)
+	}
+	if n := len(srv.FindByName("b.bin")); n != 1 {
+		t.Fatalf("b.bin files = %d, want 1", n)
+	}
+	if n := countRequests(srv, http.MethodPatch, "/upload/drive/v3/files"); n != 0 {
+		t.Fatalf("update requests = %d, want 0", n)
+	}
+}
@@ -0,0 +1,122 @@
+package gdrive
+
+import (
+	"context"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"hash"
+	"io"
+	"maps"
+	"slices"
+
+	"google.golang.org/api/drive/v3"
+)
+
+// AppPropertySHA256 上傳校驗通過後保存內容 SHA-256（十六進制）的 appProperties 鍵
+// 可通過 NewQuery().AppProperty(AppPropertySHA256, sum) 查找，或讀取 RemoteFile.AppProperties
+const AppPropertySHA256 = "sha256"
+
+// uploadedFileFields 上傳請求返回的字段（md5Checksum 用於校驗上傳內容）
+const uploadedFileFields = "id, name, md5Checksum"
+
+// uploadChecksum 上傳內容的 MD5 和 SHA-256
+type uploadChecksum struct {
+	md5    hash.Hash
+	sha256 hash.Hash
+	n      int64 // 已計算的字節數
+}
+
+// newUploadChecksum 創建校驗和計算器
+func newUploadChecksum() *uploadChecksum {
+	return &uploadChecksum{md5: md5.New(), sha256: sha256.New()}
+}
+
+// Write 實現 io.Writer
+func (s *uploadChecksum) Write(b []byte) (int, error) {
+	s.md5.Write(b)
+	s.sha256.Write(b)
+	s.n += int64(len(b))
+	return len(b), nil
+}
+
+// reader 返回讀取時計算校驗和的 io.Reader
+func (s *uploadChecksum) reader(r io.Reader) io.Reader {
+	return io.TeeReader(r, s)
+}
+
+// seek 使已計算的內容與 offset 對齊（分塊上傳時每個分塊發送前調用）
+// 未經本次上傳發送的內容（如從續傳日志恢復的已上傳部分）從 r 讀取補算；
+// offset 小於已計算的字節數（服務端只接收了部分已發送內容）時從頭重新計算
+func (s *uploadChecksum) seek(r io.ReaderAt, offset int64) error {
+	if offset < s.n {
+		*s = *newUploadChecksum()
+	}
+	if offset == s.n {
+		return nil
+	}
+	_, err := io.Copy(s, io.NewSectionReader(r, s.n, offset-s.n))
+	return err
+}
+
+// md5Hex 返回 MD5（十六進制）
+func (s *uploadChecksum) md5Hex() string {
+	return hex.EncodeToString(s.md5.Sum(nil))
+}
+
+// sha256Hex 返回 SHA-256（十六進制）
+func (s *uploadChecksum) sha256Hex() string {
+	return hex.EncodeToString(s.sha256.Sum(nil))
+}
+
+// isChecksumMismatch 判斷錯誤是否為校驗和不一致
+func isChecksumMismatch(err error) bool {
+	return errors.Is(err, ErrChecksumMismatch)
+}
+
+// verifyUpload 校驗上傳內容的 MD5 與 Drive 返回的 md5Checksum 是否一致（Drive 未返回 md5Checksum 時跳過）
+func (c *Client) verifyUpload(name string, uploaded *drive.File, sum *uploadChecksum) error {
+	if uploaded.Md5Checksum == "" {
+		return nil
+	}
+	if expected := sum.md5Hex(); uploaded.Md5Checksum != expected {
+		return c.config.newError(CodeChecksumMismatch, nil, name, expected, uploaded.Md5Checksum)
+	}
+	return nil
+}
+
+// withSHA256 返回附帶內容 SHA-256 的文件元數據副本（meta 可為 nil）
+// value 為空時清除 appProperties 中已有的值，避免內容更新後留下過期的校驗和
+func withSHA256(meta *drive.File, value string) *drive.File {
+	m := &drive.File{}
+	if meta != nil {
+		*m = *meta
+	}
+	if value == "" {
+		m.ForceSendFields = append(slices.Clone(m.ForceSendFields), "AppProperties")
+		m.NullFields = append(slices.Clone(m.NullFields), "AppProperties."+AppPropertySHA256)
+		return m
+	}
+
+	m.AppProperties = maps.Clone(m.AppProperties)
+	if m.AppProperties == nil {
+		m.AppProperties = make(map[string]string)
+	}
+	m.AppProperties[AppPropertySHA256] = value
+	return m
+}
+
+// saveSHA256 上傳內容校驗通過後將 SHA-256 保存到文件的 appProperties
+// 上傳請求中已清除舊的 SHA-256，校驗失敗或保存失敗時文件不帶 SHA-256，不會留下與內容不符的值
+func (c *Client) saveSHA256(ctx context.Context, fileID string, sum *uploadChecksum) error {
+	meta := withSHA256(nil, sum.sha256Hex())
+	err := c.retry(ctx, CodeUpdateFailed, func() error {
+		_, err := c.updateFile(fileID, meta).Fields("id").Context(ctx).Do()
+		return err
+	})
+	if err != nil {
+		return c.config.newError(CodeSaveSHA256Failed, err, fileID)
+	}
+	return nil
+}
@@ -0,0 +1,202 @@
+package gdrive_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/Digman/gdrive"
+	"github.com/Digman/gdrive/gdrivetest"
+)
+
+// corruptTransport 將上傳請求體中的 old 替換為等長的 new，模擬傳輸過程中內容損壞
+// times 為損壞的請求數，-1 表示一直損壞
+type corruptTransport struct {
+	base     http.RoundTripper
+	old, new []byte
+
+	mu    sync.Mutex
+	times int
+}
+
+func (t *corruptTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	if r.Body == nil || !strings.HasPrefix(r.URL.Path, "/upload/") {
+		return t.base.RoundTrip(r)
+	}
+	body, err := io.ReadAll(r.Body)
+	r.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	t.mu.Lock()
+	if t.times != 0 && bytes.Contains(body, t.old) {
+		body = bytes.ReplaceAll(body, t.old, t.new)
+		t.times--
+	}
+	t.mu.Unlock()
+
+	r = r.Clone(r.Context())
+	r.Body = io.NopCloser(bytes.NewReader(body))
+	return t.base.RoundTrip(r)
+}
+
+// newCorruptTransport 將上傳內容中的 "hello" 損壞為 "HELLO"，times 為損壞的請求數（-1 表示一直損壞）
+func newCorruptTransport(srv *gdrivetest.Server, times int) *corruptTransport {
+	return &corruptTransport{base: srv.HTTPClient().Transport, old: []byte("hello"), new: []byte("HELLO"), times: times}
+}
+
+// withSmallChunks 使用 256 KiB 分塊，較大的測試文件走可續傳上傳
+func withSmallChunks(c *gdrive.Config) {
+	c.UploadChunkSize = 256 << 10
+}
+
+func sha256Hex(b []byte) string {
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:])
+}
+
+// assertUploaded 檢查服務端只有一個 name 文件，內容和 SHA-256 屬性正確
+func assertUploaded(t *testing.T, srv *gdrivetest.Server, name, fileID string, content []byte) {
+	t.Helper()
+
+	files := srv.FindByName(name)
+	if len(files) != 1 || files[0].ID != fileID {
+		t.Fatalf("files named %s = %d, want one file %s", name, len(files), fileID)
+	}
+	if !bytes.Equal(files[0].Content, content) {
+		t.Fatal("uploaded content is corrupted")
+	}
+	if got, want := files[0].AppProperties[gdrive.AppPropertySHA256], sha256Hex(content); got != want {
+		t.Fatalf("sha256 = %q, want %q", got, want)
+	}
+}
+
+func TestUploadRetriesCorruptedContent(t *testing.T) {
+	tests := []struct {
+		name    string
+		content []byte
+	}{
+		{"single request", []byte("hello, drive")},
+		{"resumable", bytes.Repeat([]byte("hello, drive\n"), 300<<10/13)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := gdrivetest.NewServer()
+			defer srv.Close()
+			client := newTestClient(t, srv, newCorruptTransport(srv, 1), withSmallChunks)
+
+			fileID, err := client.UploadFile(writeTempFile(t, "a.txt", tt.content))
+			if err != nil {
+				t.Fatalf("UploadFile: %v", err)
+			}
+			assertUploaded(t, srv, "a.txt", fileID, tt.content)
+
+			// 校驗通過後只保存一次 SHA-256
+			if n := countRequests(srv, http.MethodPatch, "/drive/v3/files/"); n != 1 {
+				t.Fatalf("metadata updates = %d, want 1", n)
+			}
+		})
+	}
+}
+
+// assertNoStaleSHA256 檢查服務端文件沒有與內容不符的 SHA-256 屬性
+func assertNoStaleSHA256(t *testing.T, srv *gdrivetest.Server, fileID string) {
+	t.Helper()
+
+	f, ok := srv.File(fileID)
+	if !ok {
+		t.Fatalf("file %s not found", fileID)
+	}
+	if sum, ok := f.AppProperties[gdrive.AppPropertySHA256]; ok && sum != sha256Hex(f.Content) {
+		t.Fatalf("sha256 = %q does not match remote content", sum)
+	}
+}
+
+func TestUploadChecksumMismatchReturnsFileID(t *testing.T) {
+	srv := gdrivetest.NewServer()
+	defer srv.Close()
+	client := newTestClient(t, srv, newCorruptTransport(srv, -1), withSmallChunks)
+
+	// 每次上傳都損壞：重試用盡後返回已創建文件的 ID，不產生重複文件
+	fileID, err := client.UploadReader(context.Background(), "a.txt", bytes.NewReader([]byte("hello")), nil)
+	if !errors.Is(err, gdrive.ErrChecksumMismatch) {
+		t.Fatalf("UploadReader = %v, want ErrChecksumMismatch", err)
+	}
+	files := srv.FindByName("a.txt")
+	if fileID == "" || len(files) != 1 || files[0].ID != fileID {
+		t.Fatalf("UploadReader = %q, files = %d, want ID of the only file", fileID, len(files))
+	}
+	assertNoStaleSHA256(t, srv, fileID)
+}
+
+func TestUpdateChecksumMismatchClearsSHA256(t *testing.T) {
+	tests := []struct {
+		name    string
+		content []byte
+	}{
+		{"single request", []byte("hello, drive")},
+		{"resumable", bytes.Repeat([]byte("hello, drive\n"), 300<<10/13)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := gdrivetest.NewServer()
+			defer srv.Close()
+
+			localPath := writeTempFile(t, "a.txt", []byte("old content"))
+			fileID, err := newTestClient(t, srv, nil, withSmallChunks).UploadFile(localPath)
+			if err != nil {
+				t.Fatalf("UploadFile: %v", err)
+			}
+
+			// 更新內容每次都損壞：遠端內容已改變，舊內容的 SHA-256 不能保留
+			if err := os.WriteFile(localPath, tt.content, 0644); err != nil {
+				t.Fatal(err)
+			}
+			client := newTestClient(t, srv, newCorruptTransport(srv, -1), withSmallChunks)
+			updatedID, created, err := client.UploadOrUpdateFile(localPath)
+			if !errors.Is(err, gdrive.ErrChecksumMismatch) || created || updatedID != fileID {
+				t.Fatalf("UploadOrUpdateFile = %s, %v, %v, want ErrChecksumMismatch for %s", updatedID, created, err, fileID)
+			}
+			assertNoStaleSHA256(t, srv, fileID)
+		})
+	}
+}
+
+func TestUploadNonSeekableReaderSHA256(t *testing.T) {
+	srv := gdrivetest.NewServer()
+	defer srv.Close()
+	client := newTestClient(t, srv, newCorruptTransport(srv, 0), withSmallChunks)
+
+	// io.MultiReader 隱藏了 io.Seeker，只能在上傳時計算校驗和
+	content := []byte("streamed content")
+	fileID, created, err := client.UploadOrUpdateReader(context.Background(), "a.txt", io.MultiReader(bytes.NewReader(content)), nil)
+	if err != nil || !created {
+		t.Fatalf("UploadOrUpdateReader = %v, %v", created, err)
+	}
+	assertUploaded(t, srv, "a.txt", fileID, content)
+
+	// 保存 SHA-256 失敗時返回錯誤，並且不保留舊內容的 SHA-256
+	srv.InjectFault(gdrivetest.Fault{Method: http.MethodPatch, Path: "/drive/v3/files/", Status: http.StatusBadRequest})
+	updatedID, _, err := client.UploadOrUpdateReader(context.Background(), "a.txt", io.MultiReader(strings.NewReader("new content")), nil)
+	if gdrive.ErrorCodeOf(err) != gdrive.CodeSaveSHA256Failed || updatedID != fileID {
+		t.Fatalf("UploadOrUpdateReader = %s, %v, want %s, %s", updatedID, err, fileID, gdrive.CodeSaveSHA256Failed)
+	}
+	f, _ := srv.File(fileID)
+	if string(f.Content) != "new content" {
+		t.Fatalf("content = %q, want new content", f.Content)
+	}
+	if sum, ok := f.AppProperties[gdrive.AppPropertySHA256]; ok {
+		t.Fatalf("stale sha256 %q kept", sum)
+	}
+}
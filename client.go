@@ -1,81 +1,227 @@
 package gdrive
 
 import (
-	"fmt"
+	"context"
+	"net/http"
+	"sync"
 
+	"golang.org/x/oauth2"
 	"google.golang.org/api/drive/v3"
 	"google.golang.org/api/option"
 )
 
 // Client Google Drive 客戶端
 type Client struct {
-	config    *Config
-	service   *drive.Service
-	folderID  string           // 緩存文件夾 ID
-	scheduler *BackupScheduler // 備份調度器
+	config   *Config
+	service  *drive.Service
+	session  *authSession // 認證會話
+	driveID  string       // 目標共享雲端硬碟 ID（為空表示“我的雲端硬碟”）
+	folderID string       // 緩存文件夾 ID
+
+	schedulerMu sync.Mutex
+	scheduler   *BackupScheduler // 備份調度器（未啟動或已停止時為 nil）
+
+	httpClient *http.Client   // 已授權的 HTTP 客戶端（用於可續傳上傳，使用外部 Drive Service 時為 nil）
+	journal    *uploadJournal // 續傳日志（未配置 UploadJournalFile 時為 nil）
+
+	folderMu    sync.Mutex
+	folderCache map[string]string // 已解析的文件夾路徑到 ID 的緩存
 }
 
 // NewClient 創建新的 Google Drive 客戶端
-func NewClient(config *Config) (*Client, error) {
+// opts: 可選配置，如 WithHTTPClient、WithTokenSource、WithEndpoint、WithDriveService
+func NewClient(config *Config, opts ...Option) (*Client, error) {
+	return NewClientContext(context.Background(), config, opts...)
+}
+
+// NewClientContext 創建新的 Google Drive 客戶端
+// ctx 用於控制授權流程和初始化請求，不影響創建後客戶端的使用
+func NewClientContext(ctx context.Context, config *Config, opts ...Option) (*Client, error) {
+	options := newClientOptions(opts)
+
 	// 驗證配置
-	if err := config.Validate(); err != nil {
+	if err := config.validate(options.requiresCredentials()); err != nil {
 		return nil, err
 	}
 
 	// 獲取 OAuth2 客戶端
-	httpClient, err := getOAuth2Client(config)
+	session, err := newSession(ctx, config, options)
 	if err != nil {
-		return nil, fmt.Errorf("認證失敗: %w", err)
+		return nil, config.newError(CodeAuthFailed, err)
 	}
 
 	// 創建 Drive Service
-	service, err := drive.NewService(nil, option.WithHTTPClient(httpClient))
+	service, err := newDriveService(ctx, session, options)
 	if err != nil {
-		return nil, fmt.Errorf("創建 Drive Service 失敗: %w", err)
+		return nil, config.newError(CodeCreateServiceFailed, err)
 	}
 
 	client := &Client{
 		config:  config,
 		service: service,
+		session: session,
+		journal: newUploadJournal(config.UploadJournalFile),
 	}
+	if options.service == nil {
+		client.httpClient = session.httpClient
+	}
+
+	// 確定目標共享雲端硬碟
+	driveID, err := client.resolveSharedDrive(ctx)
+	if err != nil {
+		return nil, config.newError(CodeInitFolderFailed, err)
+	}
+	client.driveID = driveID
 
 	// 初始化時獲取或創建目標文件夾
-	folderID, err := client.GetOrCreateFolder()
+	folderID, err := client.GetOrCreateFolderContext(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("初始化文件夾失敗: %w", err)
+		return nil, config.newError(CodeInitFolderFailed, err)
 	}
 	client.folderID = folderID
 
 	return client, nil
 }
 
+// newSession 根據可選配置創建認證會話
+func newSession(ctx context.Context, config *Config, options *clientOptions) (*authSession, error) {
+	base := options.baseHTTPClient()
+	if options.service != nil || options.tokenSource != nil {
+		var tokenSource oauth2.TokenSource
+		if options.tokenSource != nil {
+			tokenSource = oauth2.ReuseTokenSource(nil, options.tokenSource)
+		}
+		return newStaticSession(config, tokenSource, config.scopes(), base, false), nil
+	}
+	return getOAuth2Client(ctx, config, base)
+}
+
+// newDriveService 根據可選配置創建 Drive Service
+func newDriveService(ctx context.Context, session *authSession, options *clientOptions) (*drive.Service, error) {
+	if options.service != nil {
+		return options.service, nil
+	}
+
+	serviceOptions := []option.ClientOption{option.WithHTTPClient(session.httpClient)}
+	if options.endpoint != "" {
+		serviceOptions = append(serviceOptions, option.WithEndpoint(options.endpoint))
+	}
+
+	service, err := drive.NewService(ctx, serviceOptions...)
+	if err != nil {
+		return nil, err
+	}
+	// 使用自定義 HTTP 客戶端時 option.WithUserAgent 不生效，直接設置到 Service
+	service.UserAgent = options.userAgent
+	return service, nil
+}
+
+// rootFolderID 返回頂層文件夾的父級 ID
+// 使用共享雲端硬碟時為共享雲端硬碟 ID，僅有 drive.appdata 權限時為應用數據文件夾，否則為 Drive 根目錄別名 "root"
+func (c *Client) rootFolderID() string {
+	if c.driveID != "" {
+		return c.driveID
+	}
+	if c.config.useAppDataFolder() {
+		return appDataFolderID
+	}
+	return myDriveRootID
+}
+
+// listFiles 創建文件列表查詢
+// 使用共享雲端硬碟時僅查詢該共享雲端硬碟；僅有 drive.appdata 權限時查詢應用數據空間
+func (c *Client) listFiles(query Query) *drive.FilesListCall {
+	call := c.service.Files.List().Q(query.String()).SupportsAllDrives(true)
+	if c.driveID != "" {
+		call = call.Corpora("drive").DriveId(c.driveID).IncludeItemsFromAllDrives(true)
+	} else if c.config.useAppDataFolder() {
+		call = call.Spaces(appDataFolderID)
+	}
+	return call
+}
+
+// createFile 創建文件創建請求（支持共享雲端硬碟）
+func (c *Client) createFile(file *drive.File) *drive.FilesCreateCall {
+	return c.service.Files.Create(file).SupportsAllDrives(true)
+}
+
+// updateFile 創建文件更新請求（支持共享雲端硬碟）
+func (c *Client) updateFile(fileID string, file *drive.File) *drive.FilesUpdateCall {
+	return c.service.Files.Update(fileID, file).SupportsAllDrives(true)
+}
+
+// SharedDriveID 獲取當前使用的共享雲端硬碟 ID（未使用共享雲端硬碟時為空字符串）
+func (c *Client) SharedDriveID() string {
+	return c.driveID
+}
+
 // GetFolderID 獲取當前使用的文件夾 ID
 func (c *Client) GetFolderID() string {
 	return c.folderID
 }
 
+// AuthState 獲取當前授權狀態
+func (c *Client) AuthState() AuthState {
+	return c.session.authState()
+}
+
+// Reauthorize 主動執行交互式授權流程並等待完成（不調用 OnReauth 回調）
+// 用於授權失效且未配置 OnReauth 時手動恢復
+func (c *Client) Reauthorize() error {
+	return c.ReauthorizeContext(context.Background())
+}
+
+// ReauthorizeContext 主動執行交互式授權流程並等待完成，ctx 取消時停止等待
+func (c *Client) ReauthorizeContext(ctx context.Context) error {
+	return c.session.reauthorize(ctx)
+}
+
 // StartBackup 啟動定時備份（非阻塞，異步執行）
 func (c *Client) StartBackup() error {
+	return c.StartBackupContext(context.Background())
+}
+
+// StartBackupContext 啟動定時備份（非阻塞，異步執行）
+// ctx 取消時停止調度並中止正在進行的上傳
+func (c *Client) StartBackupContext(ctx context.Context) error {
 	if !c.config.BackupEnabled {
-		return fmt.Errorf("備份未啟用，請在配置中設置 BackupEnabled = true")
+		return c.config.newError(CodeBackupDisabled, nil)
 	}
 
+	c.schedulerMu.Lock()
+	defer c.schedulerMu.Unlock()
+
 	if c.scheduler != nil {
-		return fmt.Errorf("備份已在運行中")
+		return c.config.newError(CodeBackupRunning, nil)
 	}
 
 	// 創建並啟動調度器
 	scheduler := NewBackupScheduler(c.config, c)
 
 	c.scheduler = scheduler
-	scheduler.Start() // 異步啟動
+	scheduler.StartContext(ctx) // 異步啟動
+
+	// ctx 取消後調度器自行退出，清除記錄以便再次啟動
+	go func() {
+		<-scheduler.Done()
+
+		c.schedulerMu.Lock()
+		defer c.schedulerMu.Unlock()
+		if c.scheduler == scheduler {
+			c.scheduler = nil
+		}
+	}()
 	return nil
 }
 
 // StopBackup 停止定時備份
 func (c *Client) StopBackup() {
-	if c.scheduler != nil {
-		c.scheduler.Stop()
-		c.scheduler = nil
+	c.schedulerMu.Lock()
+	scheduler := c.scheduler
+	c.scheduler = nil
+	c.schedulerMu.Unlock()
+
+	if scheduler != nil {
+		scheduler.Stop()
 	}
 }
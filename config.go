@@ -1,16 +1,85 @@
 package gdrive
 
 import (
-	"fmt"
 	"time"
 )
 
+// AuthFlow 交互式授權流程
+type AuthFlow string
+
+const (
+	// AuthFlowDevice Device Flow 授權（默認），適用於無瀏覽器或受限輸入設備
+	AuthFlowDevice AuthFlow = "device"
+
+	// AuthFlowLoopback 本地回環重定向授權（PKCE），適用於桌面環境，支持 drive 等敏感權限範圍
+	AuthFlowLoopback AuthFlow = "loopback"
+)
+
 // Config Google Drive 模塊配置
 type Config struct {
 	Enabled         bool   // 是否啟用
-	FolderName      string // 文件夾名稱
-	CredentialsFile string // 憑據文件路徑
-	TokenFile       string // Token 文件路徑
+	FolderName      string // 文件夾路徑，支持多級（如 "backups/prod/db"），不存在時逐級創建
+	FolderID        string // 固定使用的文件夾 ID（可選，設置後不再按名稱查找，FolderName 可為空）
+	CredentialsFile string // 憑據文件路徑（OAuth2 客戶端憑據或服務賬號密鑰，按 JSON 的 type 字段自動識別）
+	TokenFile       string // Token 文件路徑（未設置 TokenStore 時使用）
+
+	// TokenStore Token 存儲（可選，nil 則使用 TokenFile 對應的文件存儲）
+	// 可使用 NewMemoryTokenStore 或自定義實現，適用於只讀或臨時文件系統
+	TokenStore TokenStore
+
+	// AuthFlow 用戶授權流程（可選，默認 AuthFlowDevice）
+	AuthFlow AuthFlow
+
+	// OnReauth 重新授權回調（可選）
+	// Refresh Token 失效（invalid_grant）時調用，返回 nil 則重新執行交互式授權流程；nil 表示不自動重新授權
+	OnReauth ReauthFunc
+
+	// Scopes 請求的權限範圍（可選，默認 drive.file）
+	// 支持完整 URL 或簡寫，如 "drive"、"drive.readonly"、"drive.file"、"drive.appdata"
+	// 僅配置 "drive.appdata" 時文件夾創建在應用數據文件夾（appDataFolder）中
+	Scopes []string
+
+	// ImpersonateSubject 服務賬號模擬的用戶郵箱（可選，需要在 Google Workspace 中配置域範圍委派）
+	ImpersonateSubject string
+
+	// DevicePrompter 授權交互實例（可選，nil 則在終端輸出授權信息）
+	DevicePrompter DevicePrompter
+
+	// DisableBrowser 禁止自動打開系統瀏覽器
+	DisableBrowser bool
+
+	// Language 錯誤信息、日志和終端授權提示的語言（可選，默認 LanguageTraditionalChinese）
+	// 錯誤同時攜帶不隨語言變化的錯誤代碼（ErrorCode），應通過錯誤代碼判斷錯誤類型
+	Language Language
+
+	// SharedDriveID 目標共享雲端硬碟 ID（可選，為空則使用“我的雲端硬碟”）
+	// 設置後文件夾和文件的查詢、創建都限定在該共享雲端硬碟中，需要 drive 或 drive.file 權限範圍
+	SharedDriveID string
+
+	// SharedDriveName 目標共享雲端硬碟名稱（可選，未設置 SharedDriveID 時按名稱查找）
+	SharedDriveName string
+
+	// TokenEncryption Token 文件加密配置（可選，僅對默認文件存儲生效）
+	TokenEncryption *TokenEncryption
+
+	// Retry Drive API 請求的重試策略（可選，nil 使用 DefaultRetryPolicy）
+	// 遇到限流（429、403 userRateLimitExceeded）、5xx 和網絡中斷時按指數退避重試；MaxAttempts 為 1 時不重試
+	Retry *RetryPolicy
+
+	// UploadChunkSize 分塊上傳的分塊大小（字節，可選，默認 8 MiB，向上取整到 256 KiB 的倍數）
+	// 大於該大小的本地文件使用可續傳上傳，網絡中斷時只重傳未完成的分塊
+	UploadChunkSize int64
+
+	// UploadJournalFile 續傳日志文件路徑（可選，為空則不記錄）
+	// 記錄未完成的可續傳上傳會話地址和偏移，進程崩潰或重啓後 UploadFile、UploadOrUpdateFile 和定時備份從斷點繼續上傳
+	// 會話地址無需授權即可寫入，應與 Token 文件一樣妥善保管
+	UploadJournalFile string
+
+	// OnProgress 文件傳輸進度回調（可選），上傳和下載時按 ProgressInterval 間隔調用，傳輸完成時再調用一次
+	OnProgress ProgressFunc
+
+	// ProgressInterval 進度回調的最小間隔（可選，默認 500ms）
+	ProgressInterval time.Duration
 
 	// 定時備份配置
 	BackupEnabled  bool          // 是否啟用定時備份
@@ -19,32 +88,57 @@ type Config struct {
 	BackupExcludes []string      // 排除的文件模式（支持通配符，如 "*.tmp"）
 	BackupFullMode bool          // true=全量備份，false=僅備份修改的文件
 	Logger         Logger        // 日志實例（可選，nil 則使用默認實現）
+
+	// BackupQuotaPolicy 備份前的存儲空間檢查策略（可選，默認 QuotaPolicyWarn）
+	// 計劃上傳量按文件完整大小計算（更新文件時舊版本在保留期內仍佔用空間）
+	BackupQuotaPolicy QuotaPolicy
+
+	// OnBackupProgress 備份任務整體進度回調（可選），包括已處理文件數和字節數，開始上傳前和每個文件處理完成時必定調用
+	OnBackupProgress BackupProgressFunc
 }
 
 // Validate 驗證配置有效性
 func (c *Config) Validate() error {
+	return c.validate(true)
+}
+
+// validate 驗證配置有效性
+// requireCredentials: 是否要求憑據文件（使用 WithTokenSource / WithDriveService 時不需要）
+func (c *Config) validate(requireCredentials bool) error {
+	if c.Language != "" && !c.Language.valid() {
+		return c.newError(CodeInvalidLanguage, nil, c.Language)
+	}
 	if !c.Enabled {
-		return fmt.Errorf("Google Drive 模塊未啟用")
+		return c.newError(CodeDisabled, nil)
+	}
+	if requireCredentials && c.CredentialsFile == "" {
+		return c.newError(CodeMissingCredentials, nil)
 	}
-	if c.CredentialsFile == "" {
-		return fmt.Errorf("憑據文件路徑不能為空")
+	if c.FolderName == "" && c.FolderID == "" {
+		return c.newError(CodeMissingFolderName, nil)
 	}
-	if c.TokenFile == "" {
-		return fmt.Errorf("Token 文件路徑不能為空")
+	if c.AuthFlow != "" && c.AuthFlow != AuthFlowDevice && c.AuthFlow != AuthFlowLoopback {
+		return c.newError(CodeInvalidAuthFlow, nil, c.AuthFlow)
 	}
-	if c.FolderName == "" {
-		return fmt.Errorf("文件夾名稱不能為空")
+	if (c.SharedDriveID != "" || c.SharedDriveName != "") && c.useAppDataFolder() {
+		return c.newError(CodeSharedDriveAppData, nil)
 	}
 
 	// 驗證備份配置
 	if c.BackupEnabled {
 		if c.BackupInterval <= 0 {
-			return fmt.Errorf("BackupInterval 必須大於 0")
+			return c.newError(CodeInvalidBackupInterval, nil)
 		}
 		if len(c.BackupPaths) == 0 {
-			return fmt.Errorf("BackupPaths 不能為空")
+			return c.newError(CodeMissingBackupPaths, nil)
 		}
 	}
+	// 未啟用定時備份時也驗證，NewBackupScheduler 可單獨使用
+	switch c.BackupQuotaPolicy {
+	case "", QuotaPolicyWarn, QuotaPolicyDefer, QuotaPolicyNone:
+	default:
+		return c.newError(CodeInvalidQuotaPolicy, nil, c.BackupQuotaPolicy)
+	}
 
 	return nil
 }
@@ -0,0 +1,206 @@
+package gdrive
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"errors"
+	"hash"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"google.golang.org/api/drive/v3"
+)
+
+// DownloadFile 下載文件到本地路徑
+// 先寫入同目錄下的臨時文件，校驗 MD5 後原子重命名為目標文件，並恢復遠程文件的修改時間
+// fileID: 文件 ID
+// localPath: 本地文件路徑（已存在時被覆蓋）
+// 返回: 錯誤信息
+func (c *Client) DownloadFile(fileID, localPath string) error {
+	return c.DownloadFileContext(context.Background(), fileID, localPath)
+}
+
+// DownloadFileContext 下載文件到本地路徑（支持 context 取消，取消後下載立即中止）
+func (c *Client) DownloadFileContext(ctx context.Context, fileID, localPath string) error {
+	meta, err := c.getFileMetadata(ctx, fileID)
+	if err != nil {
+		return err
+	}
+
+	// 在目標目錄中創建臨時文件，保證重命名是原子操作
+	dir, name := filepath.Split(localPath)
+	if dir == "" {
+		dir = "."
+	}
+	tmp, err := os.CreateTemp(dir, "."+name+".*.tmp")
+	if err != nil {
+		return c.config.newError(CodeWriteLocalFile, err)
+	}
+	tmpPath := tmp.Name()
+	defer func() {
+		// 成功時臨時文件已被重命名，刪除失敗可以忽略
+		tmp.Close()
+		os.Remove(tmpPath)
+	}()
+
+	// 下載文件（重試時清空臨時文件重新下載）
+	progress := c.config.newTransferProgress(meta.Name, meta.Size)
+	var sum hash.Hash
+	err = c.retry(ctx, CodeDownloadFailed, func() error {
+		if _, err := tmp.Seek(0, io.SeekStart); err != nil {
+			return err
+		}
+		if err := tmp.Truncate(0); err != nil {
+			return err
+		}
+		sum = md5.New()
+		progress.set(0)
+		return c.download(ctx, fileID, io.MultiWriter(tmp, sum, progress.writer()))
+	})
+	if err != nil {
+		return c.config.newError(CodeDownloadFailed, err)
+	}
+	progress.finish()
+
+	if err := c.verifyChecksum(meta, sum); err != nil {
+		return err
+	}
+
+	if err := tmp.Sync(); err != nil {
+		return c.config.newError(CodeWriteLocalFile, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return c.config.newError(CodeWriteLocalFile, err)
+	}
+
+	// 臨時文件權限為 0600，覆蓋已有文件時沿用其權限
+	mode := os.FileMode(0o644)
+	if info, err := os.Stat(localPath); err == nil {
+		mode = info.Mode().Perm()
+	}
+	if err := os.Chmod(tmpPath, mode); err != nil {
+		return c.config.newError(CodeWriteLocalFile, err)
+	}
+
+	// 恢復遠程文件的修改時間
+	if modifiedTime, err := time.Parse(time.RFC3339, meta.ModifiedTime); err == nil {
+		if err := os.Chtimes(tmpPath, modifiedTime, modifiedTime); err != nil {
+			return c.config.newError(CodeWriteLocalFile, err)
+		}
+	}
+
+	if err := os.Rename(tmpPath, localPath); err != nil {
+		return c.config.newError(CodeWriteLocalFile, err)
+	}
+	return nil
+}
+
+// DownloadByName 按文件名在配置的文件夾中查找並下載文件
+// fileName: 文件名
+// localPath: 本地文件路徑（已存在時被覆蓋）
+// 返回: 文件 ID 和錯誤信息
+func (c *Client) DownloadByName(fileName, localPath string) (string, error) {
+	return c.DownloadByNameContext(context.Background(), fileName, localPath)
+}
+
+// DownloadByNameContext 按文件名在配置的文件夾中查找並下載文件（支持 context 取消）
+func (c *Client) DownloadByNameContext(ctx context.Context, fileName, localPath string) (string, error) {
+	fileID, err := c.findFileByName(ctx, fileName, c.folderID)
+	if err != nil {
+		return "", err
+	}
+
+	if err := c.DownloadFileContext(ctx, fileID, localPath); err != nil {
+		return "", err
+	}
+	return fileID, nil
+}
+
+// DownloadTo 下載文件內容並寫入 w
+// 寫入過程中出錯時 w 中可能已有部分內容，MD5 校驗在寫入完成後進行
+// fileID: 文件 ID
+// w: 寫入目標
+// 返回: 錯誤信息
+func (c *Client) DownloadTo(fileID string, w io.Writer) error {
+	return c.DownloadToContext(context.Background(), fileID, w)
+}
+
+// DownloadToContext 下載文件內容並寫入 w（支持 context 取消）
+func (c *Client) DownloadToContext(ctx context.Context, fileID string, w io.Writer) error {
+	meta, err := c.getFileMetadata(ctx, fileID)
+	if err != nil {
+		return err
+	}
+
+	// 僅在開始寫入前重試，已寫入的內容無法撤回
+	sum := md5.New()
+	var resp *http.Response
+	err = c.retry(ctx, CodeDownloadFailed, func() (err error) {
+		resp, err = c.downloadCall(ctx, fileID).Download()
+		return err
+	})
+	if err != nil {
+		return c.config.newError(CodeDownloadFailed, err)
+	}
+	defer resp.Body.Close()
+
+	progress := c.config.newTransferProgress(meta.Name, meta.Size)
+	if _, err := io.Copy(io.MultiWriter(w, sum, progress.writer()), resp.Body); err != nil {
+		return c.config.newError(CodeDownloadFailed, err)
+	}
+	progress.finish()
+
+	return c.verifyChecksum(meta, sum)
+}
+
+// getFileMetadata 獲取下載所需的文件元數據
+func (c *Client) getFileMetadata(ctx context.Context, fileID string) (*drive.File, error) {
+	var meta *drive.File
+	err := c.retry(ctx, CodeDownloadFailed, func() (err error) {
+		meta, err = c.service.Files.Get(fileID).
+			Fields("id, name, size, md5Checksum, modifiedTime").
+			SupportsAllDrives(true).
+			Context(ctx).
+			Do()
+		return err
+	})
+	if errors.Is(err, ErrNotFound) {
+		return nil, c.config.newError(CodeFileNotFound, nil, fileID)
+	}
+	if err != nil {
+		return nil, c.config.newError(CodeDownloadFailed, err)
+	}
+	return meta, nil
+}
+
+// downloadCall 創建文件內容下載請求（支持共享雲端硬碟）
+func (c *Client) downloadCall(ctx context.Context, fileID string) *drive.FilesGetCall {
+	return c.service.Files.Get(fileID).SupportsAllDrives(true).Context(ctx)
+}
+
+// download 下載文件內容並寫入 w
+func (c *Client) download(ctx context.Context, fileID string, w io.Writer) error {
+	resp, err := c.downloadCall(ctx, fileID).Download()
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	_, err = io.Copy(w, resp.Body)
+	return err
+}
+
+// verifyChecksum 校驗下載內容的 MD5（Drive 未返回 md5Checksum 時跳過）
+func (c *Client) verifyChecksum(meta *drive.File, sum hash.Hash) error {
+	if meta.Md5Checksum == "" {
+		return nil
+	}
+	if got := hex.EncodeToString(sum.Sum(nil)); got != meta.Md5Checksum {
+		return c.config.newError(CodeChecksumMismatch, nil, meta.Name, meta.Md5Checksum, got)
+	}
+	return nil
+}
@@ -0,0 +1,180 @@
+package gdrive_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Digman/gdrive"
+	"github.com/Digman/gdrive/gdrivetest"
+)
+
+// mediaTransport 改寫文件內容下載（alt=media）的響應體，n 為第幾次下載（從 1 開始）
+type mediaTransport struct {
+	base    http.RoundTripper
+	rewrite func(n int, body []byte) io.Reader
+	n       int
+}
+
+func (t *mediaTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	resp, err := t.base.RoundTrip(r)
+	if err != nil || r.URL.Query().Get("alt") != "media" || resp.StatusCode != http.StatusOK {
+		return resp, err
+	}
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	t.n++
+	resp.Body = io.NopCloser(t.rewrite(t.n, body))
+	return resp, nil
+}
+
+// errorAfter 讀取 r 的內容後返回 err
+type errorAfter struct {
+	r   io.Reader
+	err error
+}
+
+func (e *errorAfter) Read(p []byte) (int, error) {
+	n, err := e.r.Read(p)
+	if err == io.EOF {
+		return n, e.err
+	}
+	return n, err
+}
+
+// assertDirFiles 檢查目錄中只有 want 列出的文件（按名稱排序）
+func assertDirFiles(t *testing.T, dir string, want ...string) {
+	t.Helper()
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+	if len(names) != len(want) {
+		t.Fatalf("files in dir = %v, want %v", names, want)
+	}
+	for i := range want {
+		if names[i] != want[i] {
+			t.Fatalf("files in dir = %v, want %v", names, want)
+		}
+	}
+}
+
+func TestDownloadFile(t *testing.T) {
+	srv := gdrivetest.NewServer()
+	defer srv.Close()
+	client := newTestClient(t, srv, nil, nil)
+
+	modified := time.Date(2024, 3, 1, 8, 30, 0, 0, time.UTC)
+	content := []byte("hello, drive")
+	fileID := srv.AddFile(gdrivetest.File{Name: "a.txt", Content: content, ModifiedTime: modified})
+
+	dir := t.TempDir()
+	localPath := filepath.Join(dir, "a.txt")
+	if err := client.DownloadFile(fileID, localPath); err != nil {
+		t.Fatalf("DownloadFile: %v", err)
+	}
+
+	got, err := os.ReadFile(localPath)
+	if err != nil || !bytes.Equal(got, content) {
+		t.Fatalf("content = %q, %v, want %q", got, err, content)
+	}
+	info, err := os.Stat(localPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !info.ModTime().Equal(modified) {
+		t.Fatalf("mtime = %s, want %s", info.ModTime(), modified)
+	}
+	assertDirFiles(t, dir, "a.txt")
+}
+
+func TestDownloadFileRetriesInterruptedTransfer(t *testing.T) {
+	srv := gdrivetest.NewServer()
+	defer srv.Close()
+
+	// 第一次下載傳輸到一半連接中斷
+	transport := &mediaTransport{
+		base: srv.HTTPClient().Transport,
+		rewrite: func(n int, body []byte) io.Reader {
+			if n == 1 {
+				return &errorAfter{r: bytes.NewReader(body[:len(body)/2]), err: io.ErrUnexpectedEOF}
+			}
+			return bytes.NewReader(body)
+		},
+	}
+	client := newTestClient(t, srv, transport, nil)
+
+	content := bytes.Repeat([]byte("0123456789"), 1000)
+	fileID := srv.AddFile(gdrivetest.File{Name: "a.bin", Content: content})
+
+	localPath := filepath.Join(t.TempDir(), "a.bin")
+	if err := client.DownloadFile(fileID, localPath); err != nil {
+		t.Fatalf("DownloadFile: %v", err)
+	}
+	if got, _ := os.ReadFile(localPath); !bytes.Equal(got, content) {
+		t.Fatalf("content length = %d, want %d", len(got), len(content))
+	}
+	if transport.n != 2 {
+		t.Fatalf("downloads = %d, want 2", transport.n)
+	}
+}
+
+func TestDownloadChecksumMismatch(t *testing.T) {
+	srv := gdrivetest.NewServer()
+	defer srv.Close()
+
+	// 每次下載都返回被篡改的內容
+	client := newTestClient(t, srv, &mediaTransport{
+		base: srv.HTTPClient().Transport,
+		rewrite: func(_ int, body []byte) io.Reader {
+			corrupted := bytes.Clone(body)
+			corrupted[0] ^= 0xff
+			return bytes.NewReader(corrupted)
+		},
+	}, nil)
+	fileID := srv.AddFile(gdrivetest.File{Name: "a.txt", Content: []byte("hello")})
+
+	// 校驗失敗時保留原有文件，不留下臨時文件
+	dir := t.TempDir()
+	localPath := filepath.Join(dir, "a.txt")
+	if err := os.WriteFile(localPath, []byte("old"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	err := client.DownloadFile(fileID, localPath)
+	if !errors.Is(err, gdrive.ErrChecksumMismatch) || gdrive.ErrorCodeOf(err) != gdrive.CodeChecksumMismatch {
+		t.Fatalf("DownloadFile = %v, want %s", err, gdrive.CodeChecksumMismatch)
+	}
+	if got, _ := os.ReadFile(localPath); string(got) != "old" {
+		t.Fatalf("content = %q, want old", got)
+	}
+	assertDirFiles(t, dir, "a.txt")
+
+	var buf bytes.Buffer
+	if err := client.DownloadTo(fileID, &buf); !errors.Is(err, gdrive.ErrChecksumMismatch) {
+		t.Fatalf("DownloadTo = %v, want ErrChecksumMismatch", err)
+	}
+}
+
+func TestDownloadByNameNotFound(t *testing.T) {
+	srv := gdrivetest.NewServer()
+	defer srv.Close()
+	client := newTestClient(t, srv, nil, nil)
+
+	_, err := client.DownloadByName("missing.txt", filepath.Join(t.TempDir(), "missing.txt"))
+	if !errors.Is(err, gdrive.ErrNotFound) {
+		t.Fatalf("DownloadByName = %v, want ErrNotFound", err)
+	}
+}
@@ -0,0 +1,105 @@
+package gdrive
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"google.golang.org/api/googleapi"
+)
+
+// 可通過 errors.Is 判斷的錯誤類型
+// 這些錯誤僅作為判斷依據，文本固定為英文；本地化的錯誤信息見 *Error 和 *APIError
+var (
+	// ErrNotFound 文件或文件夾不存在（包括 Drive API 返回 404）
+	ErrNotFound = errors.New("not found")
+
+	// ErrUnauthorized 授權無效或已失效（Refresh Token 被撤銷、Drive API 返回 401）
+	ErrUnauthorized = errors.New("unauthorized")
+
+	// ErrQuotaExceeded 存儲空間已滿（Drive API 返回 403 storageQuotaExceeded）
+	ErrQuotaExceeded = errors.New("storage quota exceeded")
+
+	// ErrRateLimited 請求頻率超過限制（Drive API 返回 429 或 403 userRateLimitExceeded）
+	ErrRateLimited = errors.New("rate limit exceeded")
+
+	// ErrConflict 資源衝突（Drive API 返回 409 或 412）
+	ErrConflict = errors.New("conflict")
+
+	// ErrChecksumMismatch 傳輸內容的校驗和與 Drive 返回的 md5Checksum 不一致
+	ErrChecksumMismatch = errors.New("checksum mismatch")
+)
+
+// APIError Drive API 返回的錯誤，可通過 errors.As 獲取 HTTP 狀態碼和錯誤原因
+// 同時支持 errors.Is 判斷 ErrNotFound、ErrUnauthorized、ErrQuotaExceeded、ErrRateLimited、ErrConflict
+type APIError struct {
+	Code    int    // HTTP 狀態碼
+	Reason  string // 錯誤原因（如 "userRateLimitExceeded"、"storageQuotaExceeded"）
+	Message string // 錯誤信息
+	Err     error  // 原始錯誤（*googleapi.Error）
+
+	language Language // 錯誤信息語言
+}
+
+// Error 實現 error 接口
+func (e *APIError) Error() string {
+	if e.Reason != "" {
+		return fmt.Sprintf(e.language.text(msgAPIErrorReason), e.Code, e.Reason, e.Message)
+	}
+	return fmt.Sprintf(e.language.text(msgAPIError), e.Code, e.Message)
+}
+
+// Unwrap 返回原始錯誤
+func (e *APIError) Unwrap() error {
+	return e.Err
+}
+
+// Is 按 HTTP 狀態碼和錯誤原因匹配錯誤類型
+func (e *APIError) Is(target error) bool {
+	switch target {
+	case ErrNotFound:
+		return e.Code == http.StatusNotFound
+	case ErrUnauthorized:
+		return e.Code == http.StatusUnauthorized
+	case ErrQuotaExceeded:
+		return e.Code == http.StatusForbidden && (e.Reason == "storageQuotaExceeded" || e.Reason == "quotaExceeded")
+	case ErrRateLimited:
+		return e.Code == http.StatusTooManyRequests ||
+			(e.Code == http.StatusForbidden && (e.Reason == "userRateLimitExceeded" || e.Reason == "rateLimitExceeded"))
+	case ErrConflict:
+		return e.Code == http.StatusConflict || e.Code == http.StatusPreconditionFailed
+	default:
+		return false
+	}
+}
+
+// wrapAPIError 將 googleapi.Error 轉換為 APIError，其他錯誤原樣返回
+func wrapAPIError(err error, language Language) error {
+	var apiErr *googleapi.Error
+	if err == nil || !errors.As(err, &apiErr) {
+		return err
+	}
+
+	// 已轉換過
+	var wrapped *APIError
+	if errors.As(err, &wrapped) {
+		return err
+	}
+
+	e := &APIError{
+		Code:     apiErr.Code,
+		Message:  apiErr.Message,
+		Err:      err,
+		language: language,
+	}
+	if len(apiErr.Errors) > 0 {
+		e.Reason = apiErr.Errors[0].Reason
+		if e.Message == "" {
+			e.Message = apiErr.Errors[0].Message
+		}
+	}
+	if e.Message == "" {
+		e.Message = http.StatusText(apiErr.Code)
+	}
+	return e
+}
@@ -1,9 +1,14 @@
 package gdrive
 
 import (
-	"fmt"
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"errors"
+	"io"
 	"os"
 	"path/filepath"
+	"time"
 
 	"google.golang.org/api/drive/v3"
 )
@@ -12,114 +17,254 @@ import (
 // localPath: 本地文件路徑
 // 返回: 文件 ID 和錯誤信息
 func (c *Client) UploadFile(localPath string) (string, error) {
-	// 打開本地文件
-	file, err := os.Open(localPath)
-	if err != nil {
-		return "", fmt.Errorf("無法打開本地文件: %w", err)
-	}
-	defer file.Close()
-
-	// 獲取文件名
-	fileName := filepath.Base(localPath)
-
-	// 創建 Drive 文件元數據
-	driveFile := &drive.File{
-		Name:    fileName,
-		Parents: []string{c.folderID},
-	}
-
-	// 上傳文件
-	createdFile, err := c.service.Files.Create(driveFile).
-		Media(file).
-		Fields("id, name").
-		Do()
-	if err != nil {
-		return "", fmt.Errorf("上傳文件失敗: %w", err)
-	}
+	return c.UploadFileContext(context.Background(), localPath)
+}
 
-	return createdFile.Id, nil
+// UploadFileContext 上傳文件到配置的文件夾（支持 context 取消，取消後上傳立即中止）
+func (c *Client) UploadFileContext(ctx context.Context, localPath string) (string, error) {
+	return c.uploadLocalFile(ctx, localPath, "", nil)
 }
 
 // UpdateFile 更新已存在的文件（按名稱查找並覆蓋）
 // localPath: 本地文件路徑
 // 返回: 文件 ID 和錯誤信息
 func (c *Client) UpdateFile(localPath string) (string, error) {
+	return c.UpdateFileContext(context.Background(), localPath)
+}
+
+// UpdateFileContext 更新已存在的文件（支持 context 取消）
+func (c *Client) UpdateFileContext(ctx context.Context, localPath string) (string, error) {
 	// 獲取文件名
 	fileName := filepath.Base(localPath)
 
 	// 查找已存在的文件
-	fileID, err := c.findFileByName(fileName, c.folderID)
+	fileID, err := c.findFileByName(ctx, fileName, c.folderID)
 	if err != nil {
-		return "", fmt.Errorf("查找文件失敗: %w", err)
+		return "", c.config.newError(CodeFindFileFailed, err)
 	}
 
-	// 打開本地文件
-	file, err := os.Open(localPath)
-	if err != nil {
-		return "", fmt.Errorf("無法打開本地文件: %w", err)
-	}
-	defer file.Close()
-
 	// 更新文件內容
-	updatedFile, err := c.service.Files.Update(fileID, nil).
-		Media(file).
-		Fields("id, name").
-		Do()
-	if err != nil {
-		return "", fmt.Errorf("更新文件失敗: %w", err)
-	}
-
-	return updatedFile.Id, nil
+	return c.uploadLocalFile(ctx, localPath, fileID, nil)
 }
 
-// UploadOrUpdateFile 智能上傳：不存在則創建，存在則更新
+// UploadStatus 智能上傳的結果
+type UploadStatus string
+
+const (
+	UploadCreated   UploadStatus = "created"   // 文件不存在，已創建
+	UploadUpdated   UploadStatus = "updated"   // 文件已存在且內容不同，已更新
+	UploadUnchanged UploadStatus = "unchanged" // 文件已存在且內容相同，跳過上傳
+)
+
+// UploadOrUpdateFile 智能上傳：不存在則創建，存在則更新（內容與遠程文件相同時跳過上傳）
 // localPath: 本地文件路徑
 // 返回: 文件 ID、是否為新創建、錯誤信息
 func (c *Client) UploadOrUpdateFile(localPath string) (string, bool, error) {
-	// 獲取文件名
-	fileName := filepath.Base(localPath)
+	return c.UploadOrUpdateFileContext(context.Background(), localPath)
+}
+
+// UploadOrUpdateFileContext 智能上傳（支持 context 取消）
+func (c *Client) UploadOrUpdateFileContext(ctx context.Context, localPath string) (string, bool, error) {
+	fileID, status, err := c.uploadOrUpdateFile(ctx, localPath, nil)
+	return fileID, status == UploadCreated, err
+}
 
-	// 嘗試查找已存在的文件
-	fileID, err := c.findFileByName(fileName, c.folderID)
+// SyncFile 同步文件到配置的文件夾：不存在則創建，內容不同則更新，內容相同則跳過
+// 與 UploadOrUpdateFile 相同，但返回具體的處理結果
+// localPath: 本地文件路徑
+// 返回: 文件 ID、處理結果、錯誤信息
+func (c *Client) SyncFile(localPath string) (string, UploadStatus, error) {
+	return c.SyncFileContext(context.Background(), localPath)
+}
+
+// SyncFileContext 同步文件到配置的文件夾（支持 context 取消）
+func (c *Client) SyncFileContext(ctx context.Context, localPath string) (string, UploadStatus, error) {
+	return c.uploadOrUpdateFile(ctx, localPath, nil)
+}
+
+// uploadOrUpdateFile 智能上傳，onProgress 為本次上傳的額外進度回調（可為 nil）
+func (c *Client) uploadOrUpdateFile(ctx context.Context, localPath string, onProgress ProgressFunc) (string, UploadStatus, error) {
+	existing, unchanged, err := c.findRemote(ctx, localPath)
+	if err != nil {
+		return "", "", err
+	}
+
+	// 內容與遠程文件相同時跳過上傳，避免浪費流量和產生多餘的版本
+	if unchanged {
+		return existing.Id, UploadUnchanged, nil
+	}
+	return c.uploadChanged(ctx, localPath, existing, onProgress)
+}
+
+// findRemote 在配置的文件夾中查找與本地文件同名的遠程文件，並判斷內容是否相同
+// 返回: 遠程文件（不存在時為 nil）、內容是否相同、錯誤信息
+func (c *Client) findRemote(ctx context.Context, localPath string) (*drive.File, bool, error) {
+	existing, err := c.findFile(ctx, filepath.Base(localPath), c.folderID)
+	if errors.Is(err, ErrNotFound) {
+		return nil, false, nil
+	}
 	if err != nil {
-		// 文件不存在，執行上傳
-		fileID, err := c.UploadFile(localPath)
+		// 查詢失敗（網絡錯誤、限流等）時不能確定文件是否存在，直接返回避免創建重複文件
+		return nil, false, c.config.newError(CodeFindFileFailed, err)
+	}
+
+	unchanged, err := c.sameContent(localPath, existing)
+	if err != nil {
+		return nil, false, err
+	}
+	return existing, unchanged, nil
+}
+
+// uploadChanged 上傳內容與遠程文件不同的本地文件：existing 為 nil 時創建，否則更新
+// 文件已創建後失敗（如內容校驗失敗）時同時返回文件 ID
+func (c *Client) uploadChanged(ctx context.Context, localPath string, existing *drive.File, onProgress ProgressFunc) (string, UploadStatus, error) {
+	if existing == nil {
+		fileID, err := c.uploadLocalFile(ctx, localPath, "", onProgress)
 		if err != nil {
-			return "", false, err
+			return fileID, "", err
 		}
-		return fileID, true, nil
+		return fileID, UploadCreated, nil
 	}
 
-	// 文件已存在，執行更新
-	fileID, err = c.UpdateFile(localPath)
+	fileID, err := c.uploadLocalFile(ctx, localPath, existing.Id, onProgress)
 	if err != nil {
-		return "", false, err
+		return fileID, "", err
 	}
-	return fileID, false, nil
+	return fileID, UploadUpdated, nil
+}
+
+// sameContent 判斷本地文件與遠程文件內容是否相同
+// 先比較大小，一致時才計算本地文件的 MD5；遠程文件沒有 md5Checksum（如 Google 文檔）時視為不同
+func (c *Client) sameContent(localPath string, remote *drive.File) (bool, error) {
+	if remote.Md5Checksum == "" {
+		return false, nil
+	}
+
+	file, err := os.Open(localPath)
+	if err != nil {
+		return false, c.config.newError(CodeOpenLocalFile, err)
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return false, c.config.newError(CodeOpenLocalFile, err)
+	}
+	if info.Size() != remote.Size {
+		return false, nil
+	}
+
+	sum := md5.New()
+	if _, err := io.Copy(sum, file); err != nil {
+		return false, c.config.newError(CodeOpenLocalFile, err)
+	}
+	return hex.EncodeToString(sum.Sum(nil)) == remote.Md5Checksum, nil
 }
 
 // findFileByName 根據文件名在指定文件夾中查找文件
 // fileName: 文件名
 // folderID: 文件夾 ID
 // 返回: 文件 ID 和錯誤信息
-func (c *Client) findFileByName(fileName, folderID string) (string, error) {
+func (c *Client) findFileByName(ctx context.Context, fileName, folderID string) (string, error) {
+	file, err := c.findFile(ctx, fileName, folderID)
+	if err != nil {
+		return "", err
+	}
+	return file.Id, nil
+}
+
+// findFile 根據文件名在指定文件夾中查找文件，返回 ID、大小和 MD5
+func (c *Client) findFile(ctx context.Context, fileName, folderID string) (*drive.File, error) {
 	// 構建查詢條件：文件名匹配、在指定文件夾中、未刪除
-	query := fmt.Sprintf("name='%s' and '%s' in parents and trashed=false", fileName, folderID)
+	query := NewQuery().Name(fileName).InParents(folderID).Trashed(false)
 
 	// 執行查詢
-	fileList, err := c.service.Files.List().
-		Q(query).
-		Fields("files(id, name)").
-		PageSize(1).
-		Do()
+	var fileList *drive.FileList
+	err := c.retry(ctx, CodeQueryFilesFailed, func() (err error) {
+		fileList, err = c.listFiles(query).
+			Fields("files(id, name, size, md5Checksum)").
+			PageSize(1).
+			Context(ctx).
+			Do()
+		return err
+	})
 	if err != nil {
-		return "", fmt.Errorf("查詢文件失敗: %w", err)
+		return nil, c.config.newError(CodeQueryFilesFailed, err)
 	}
 
 	// 檢查結果
 	if len(fileList.Files) == 0 {
-		return "", fmt.Errorf("文件不存在: %s", fileName)
+		return nil, c.config.newError(CodeFileNotFound, nil, fileName)
 	}
 
-	return fileList.Files[0].Id, nil
+	return fileList.Files[0], nil
+}
+
+// RemoteFile Drive 中的文件信息
+type RemoteFile struct {
+	ID            string            // 文件 ID
+	Name          string            // 文件名
+	MimeType      string            // MIME 類型
+	Size          int64             // 文件大小（字節，Google 文檔等在線文件為 0）
+	MD5Checksum   string            // 文件內容的 MD5（十六進制，Google 文檔等在線文件為空）
+	ModifiedTime  time.Time         // 修改時間
+	Parents       []string          // 父文件夾 ID
+	AppProperties map[string]string // 應用私有屬性
+}
+
+// remoteFileFields 查詢 RemoteFile 所需的字段
+const remoteFileFields = "id, name, mimeType, size, md5Checksum, modifiedTime, parents, appProperties"
+
+// FindFiles 按查詢條件查找文件
+// 查詢範圍與客戶端一致（共享雲端硬碟或應用數據文件夾），不限於配置的文件夾，需要時使用 InParents(client.GetFolderID())
+// query: 查詢條件
+// 返回: 匹配的文件列表和錯誤信息
+func (c *Client) FindFiles(query Query) ([]RemoteFile, error) {
+	return c.FindFilesContext(context.Background(), query)
+}
+
+// FindFilesContext 按查詢條件查找文件（支持 context 取消）
+func (c *Client) FindFilesContext(ctx context.Context, query Query) ([]RemoteFile, error) {
+	var files []RemoteFile
+	pageToken := ""
+
+	for {
+		var fileList *drive.FileList
+		err := c.retry(ctx, CodeQueryFilesFailed, func() (err error) {
+			fileList, err = c.listFiles(query).
+				Fields("nextPageToken, files(" + remoteFileFields + ")").
+				PageSize(100).
+				PageToken(pageToken).
+				Context(ctx).
+				Do()
+			return err
+		})
+		if err != nil {
+			return nil, c.config.newError(CodeQueryFilesFailed, err)
+		}
+
+		for _, f := range fileList.Files {
+			files = append(files, newRemoteFile(f))
+		}
+
+		if fileList.NextPageToken == "" {
+			return files, nil
+		}
+		pageToken = fileList.NextPageToken
+	}
+}
+
+// newRemoteFile 將 Drive API 返回的文件轉換為 RemoteFile
+func newRemoteFile(f *drive.File) RemoteFile {
+	modifiedTime, _ := time.Parse(time.RFC3339, f.ModifiedTime)
+	return RemoteFile{
+		ID:            f.Id,
+		Name:          f.Name,
+		MimeType:      f.MimeType,
+		Size:          f.Size,
+		MD5Checksum:   f.Md5Checksum,
+		ModifiedTime:  modifiedTime,
+		Parents:       f.Parents,
+		AppProperties: f.AppProperties,
+	}
 }
@@ -1,83 +1,236 @@
 package gdrive
 
 import (
-	"fmt"
+	"context"
+	"errors"
+	"strings"
 
 	"google.golang.org/api/drive/v3"
 )
 
+// folderMimeType 文件夾的 MIME 類型
+const folderMimeType = "application/vnd.google-apps.folder"
+
+// myDriveRootID 我的雲端硬碟根目錄的別名，可用於 parents 和 "'root' in parents" 查詢
+const myDriveRootID = "root"
+
 // CreateFolder 創建文件夾
 // folderName: 文件夾名稱
-// parentID: 父文件夾 ID（空字符串表示根目錄）
+// parentID: 父文件夾 ID（空字符串表示頂層：共享雲端硬碟、應用數據文件夾或我的雲端硬碟的根目錄）
 // 返回: 文件夾 ID 和錯誤信息
 func (c *Client) CreateFolder(folderName, parentID string) (string, error) {
+	return c.CreateFolderContext(context.Background(), folderName, parentID)
+}
+
+// CreateFolderContext 創建文件夾（支持 context 取消）
+// 服務端可能已創建文件夾的錯誤（5xx、超時）不會重試，避免產生重複文件夾
+func (c *Client) CreateFolderContext(ctx context.Context, folderName, parentID string) (string, error) {
+	return c.createFolder(ctx, folderName, parentID, nil)
+}
+
+// createFolder 創建文件夾
+// lookup: 查找已創建的同名文件夾（返回空字符串表示不存在），為 nil 時結果不確定的創建請求不重試
+func (c *Client) createFolder(ctx context.Context, folderName, parentID string, lookup func() (string, error)) (string, error) {
 	folder := &drive.File{
 		Name:     folderName,
-		MimeType: "application/vnd.google-apps.folder",
+		MimeType: folderMimeType,
+	}
+
+	// 未指定父文件夾時創建在頂層（共享雲端硬碟或應用數據文件夾中不能省略父級，否則會創建到我的雲端硬碟）
+	if parentID == "" {
+		parentID = c.rootFolderID()
 	}
+	folder.Parents = []string{parentID}
 
-	// 如果指定了父文件夾，則設置父級
-	if parentID != "" {
-		folder.Parents = []string{parentID}
+	var folderID string
+	var find func() (bool, error)
+	if lookup != nil {
+		find = func() (found bool, err error) {
+			folderID, err = lookup()
+			return folderID != "", err
+		}
 	}
 
 	// 創建文件夾
-	createdFolder, err := c.service.Files.Create(folder).
-		Fields("id, name").
-		Do()
+	err := c.retryCreate(ctx, CodeCreateFolderFailed, find, func() error {
+		createdFolder, err := c.createFile(folder).
+			Fields("id, name").
+			Context(ctx).
+			Do()
+		if err != nil {
+			return err
+		}
+		folderID = createdFolder.Id
+		return nil
+	})
 	if err != nil {
-		return "", fmt.Errorf("創建文件夾失敗: %w", err)
+		return "", c.config.newError(CodeCreateFolderFailed, err)
 	}
 
-	return createdFolder.Id, nil
+	return folderID, nil
 }
 
-// GetOrCreateFolder 獲取或創建文件夾（不存在則創建）
+// GetOrCreateFolder 獲取或創建配置的目標文件夾（不存在則逐級創建）
+// 配置了 FolderID 時直接使用該文件夾
 // 返回: 文件夾 ID 和錯誤信息
 func (c *Client) GetOrCreateFolder() (string, error) {
-	folderName := c.config.FolderName
+	return c.GetOrCreateFolderContext(context.Background())
+}
+
+// GetOrCreateFolderContext 獲取或創建配置的目標文件夾（支持 context 取消）
+func (c *Client) GetOrCreateFolderContext(ctx context.Context) (string, error) {
+	if c.config.FolderID != "" {
+		return c.checkFolder(ctx, c.config.FolderID)
+	}
+	return c.MkdirAllContext(ctx, c.config.FolderName)
+}
+
+// MkdirAll 按路徑逐級獲取或創建文件夾（如 "backups/prod/db"），返回最後一級文件夾 ID
+// 路徑相對於 Drive 根目錄（使用共享雲端硬碟時為共享雲端硬碟根目錄，僅有 drive.appdata 權限時為應用數據文件夾）
+// 已解析的路徑會被緩存，後續調用不再查詢
+func (c *Client) MkdirAll(folderPath string) (string, error) {
+	return c.MkdirAllContext(context.Background(), folderPath)
+}
+
+// MkdirAllContext 按路徑逐級獲取或創建文件夾（支持 context 取消）
+func (c *Client) MkdirAllContext(ctx context.Context, folderPath string) (string, error) {
+	segments, err := c.splitFolderPath(folderPath)
+	if err != nil {
+		return "", err
+	}
+
+	// 串行解析，避免並發調用時創建重複文件夾
+	c.folderMu.Lock()
+	defer c.folderMu.Unlock()
+
+	if c.folderCache == nil {
+		c.folderCache = make(map[string]string)
+	}
+
+	parentID := c.rootFolderID()
+	for i, name := range segments {
+		key := strings.Join(segments[:i+1], "/")
+		if id, ok := c.folderCache[key]; ok {
+			parentID = id
+			continue
+		}
+
+		id, err := c.getOrCreateChildFolder(ctx, name, parentID)
+		if err != nil {
+			return "", err
+		}
+		c.folderCache[key] = id
+		parentID = id
+	}
+
+	return parentID, nil
+}
 
+// getOrCreateChildFolder 在父文件夾下獲取或創建指定名稱的文件夾
+func (c *Client) getOrCreateChildFolder(ctx context.Context, folderName, parentID string) (string, error) {
 	// 先嘗試查找文件夾
-	folderID, err := c.findFolderByName(folderName, "")
+	folderID, err := c.findFolderByName(ctx, folderName, parentID)
 	if err == nil {
 		// 文件夾已存在
 		return folderID, nil
 	}
+	if !errors.Is(err, ErrNotFound) {
+		// 查詢失敗時不能確定文件夾是否存在，直接返回避免創建重複文件夾
+		return "", err
+	}
+
+	// 文件夾不存在，創建新文件夾；創建結果不確定時重新查找，避免重試產生重複文件夾
+	return c.createFolder(ctx, folderName, parentID, func() (string, error) {
+		return c.queryFolder(ctx, folderName, parentID)
+	})
+}
 
-	// 文件夾不存在，創建新文件夾
-	folderID, err = c.CreateFolder(folderName, "")
+// splitFolderPath 將文件夾路徑拆分為各級名稱（忽略空段和 "."，不支持 ".."）
+func (c *Client) splitFolderPath(folderPath string) ([]string, error) {
+	var segments []string
+	for _, segment := range strings.Split(folderPath, "/") {
+		segment = strings.TrimSpace(segment)
+		switch segment {
+		case "", ".":
+			continue
+		case "..":
+			return nil, c.config.newError(CodeInvalidFolderPath, nil, folderPath)
+		}
+		segments = append(segments, segment)
+	}
+
+	if len(segments) == 0 {
+		return nil, c.config.newError(CodeInvalidFolderPath, nil, folderPath)
+	}
+	return segments, nil
+}
+
+// checkFolder 檢查指定 ID 的文件夾是否存在且未被刪除
+func (c *Client) checkFolder(ctx context.Context, folderID string) (string, error) {
+	var folder *drive.File
+	err := c.retry(ctx, CodeQueryFolderFailed, func() (err error) {
+		folder, err = c.service.Files.Get(folderID).
+			Fields("id, mimeType, trashed").
+			SupportsAllDrives(true).
+			Context(ctx).
+			Do()
+		return err
+	})
+	if errors.Is(err, ErrNotFound) {
+		return "", c.config.newError(CodeFolderNotFound, nil, folderID)
+	}
 	if err != nil {
-		return "", fmt.Errorf("創建文件夾失敗: %w", err)
+		return "", c.config.newError(CodeQueryFolderFailed, err)
 	}
 
-	return folderID, nil
+	if folder.MimeType != folderMimeType || folder.Trashed {
+		return "", c.config.newError(CodeFolderNotFound, nil, folderID)
+	}
+	return folder.Id, nil
 }
 
 // findFolderByName 根據名稱查找文件夾
 // folderName: 文件夾名稱
-// parentID: 父文件夾 ID（空字符串表示在根目錄查找）
+// parentID: 父文件夾 ID（空字符串表示在頂層查找，見 rootFolderID）
 // 返回: 文件夾 ID 和錯誤信息
-func (c *Client) findFolderByName(folderName, parentID string) (string, error) {
-	// 構建查詢條件
-	query := fmt.Sprintf("name='%s' and mimeType='application/vnd.google-apps.folder' and trashed=false", folderName)
-	if parentID != "" {
-		query = fmt.Sprintf("%s and '%s' in parents", query, parentID)
+func (c *Client) findFolderByName(ctx context.Context, folderName, parentID string) (string, error) {
+	var folderID string
+	err := c.retry(ctx, CodeQueryFolderFailed, func() (err error) {
+		folderID, err = c.queryFolder(ctx, folderName, parentID)
+		return err
+	})
+	if err != nil {
+		return "", c.config.newError(CodeQueryFolderFailed, err)
 	}
 
-	// 執行查詢
-	fileList, err := c.service.Files.List().
-		Q(query).
+	// 檢查結果
+	if folderID == "" {
+		return "", c.config.newError(CodeFolderNotFound, nil, folderName)
+	}
+
+	return folderID, nil
+}
+
+// queryFolder 查詢父文件夾下指定名稱的文件夾（不重試），不存在時返回空字符串
+// 只查詢直接子文件夾，其他位置的同名文件夾不會匹配
+func (c *Client) queryFolder(ctx context.Context, folderName, parentID string) (string, error) {
+	if parentID == "" {
+		parentID = c.rootFolderID()
+	}
+
+	// 構建查詢條件
+	query := NewQuery().Name(folderName).MimeType(folderMimeType).Trashed(false).InParents(parentID)
+
+	fileList, err := c.listFiles(query).
 		Fields("files(id, name)").
 		PageSize(1).
+		Context(ctx).
 		Do()
 	if err != nil {
-		return "", fmt.Errorf("查詢文件夾失敗: %w", err)
+		return "", err
 	}
-
-	// 檢查結果
 	if len(fileList.Files) == 0 {
-		return "", fmt.Errorf("文件夾不存在: %s", folderName)
+		return "", nil
 	}
-
 	return fileList.Files[0].Id, nil
 }
@@ -0,0 +1,49 @@
+package gdrive_test
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/Digman/gdrive"
+	"github.com/Digman/gdrive/gdrivetest"
+)
+
+func TestMkdirAllIgnoresNestedFolder(t *testing.T) {
+	srv := gdrivetest.NewServer()
+	defer srv.Close()
+
+	// 其他文件夾下的同名文件夾不應被當作頂層文件夾
+	otherID := srv.AddFile(gdrivetest.File{Name: "other", MimeType: gdrivetest.FolderMimeType})
+	nestedID := srv.AddFile(gdrivetest.File{Name: "backups", MimeType: gdrivetest.FolderMimeType, Parents: []string{otherID}})
+
+	client, err := srv.NewClient(&gdrive.Config{FolderName: "backups", Logger: testLogger{t}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	folderID, err := client.MkdirAll("backups/db")
+	if err != nil {
+		t.Fatalf("MkdirAll: %v", err)
+	}
+	db, ok := srv.File(folderID)
+	if !ok {
+		t.Fatalf("folder %s not found", folderID)
+	}
+	top, ok := srv.File(db.Parents[0])
+	if !ok || top.ID == nestedID || !slices.Equal(top.Parents, []string{gdrivetest.RootID}) {
+		t.Fatalf("db parent = %+v, want a new top-level folder", top)
+	}
+
+	// 頂層文件夾已存在時直接使用
+	client2, err := srv.NewClient(&gdrive.Config{FolderName: "backups", Logger: testLogger{t}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	folderID2, err := client2.MkdirAll("backups/db")
+	if err != nil {
+		t.Fatalf("MkdirAll: %v", err)
+	}
+	if folderID2 != folderID {
+		t.Fatalf("MkdirAll = %s, want %s", folderID2, folderID)
+	}
+}
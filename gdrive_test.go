@@ -0,0 +1,78 @@
+package gdrive_test
+
+import (
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Digman/gdrive"
+	"github.com/Digman/gdrive/gdrivetest"
+)
+
+// hasErrorCode 判斷錯誤鏈中是否存在指定錯誤代碼的 *gdrive.Error
+func hasErrorCode(err error, code gdrive.ErrorCode) bool {
+	for ; err != nil; err = errors.Unwrap(err) {
+		if e, ok := err.(*gdrive.Error); ok && e.Code == code {
+			return true
+		}
+	}
+	return false
+}
+
+// testLogger 將日志輸出到 t.Logf
+type testLogger struct {
+	t testing.TB
+}
+
+func (l testLogger) Infof(format string, v ...interface{})    { l.t.Logf(format, v...) }
+func (l testLogger) Warningf(format string, v ...interface{}) { l.t.Logf(format, v...) }
+func (l testLogger) Errorf(format string, v ...interface{})   { l.t.Logf(format, v...) }
+
+// writeTempFile 在臨時目錄中創建文件，返回文件路徑
+func writeTempFile(t testing.TB, name string, content []byte) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, content, 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// fastRetry 測試用重試策略（不等待真實的退避時間）
+func fastRetry(maxAttempts int) *gdrive.RetryPolicy {
+	return &gdrive.RetryPolicy{
+		MaxAttempts: maxAttempts,
+		BaseDelay:   time.Millisecond,
+		MaxDelay:    10 * time.Millisecond,
+	}
+}
+
+// newTestClient 創建連接模擬服務的客戶端（目標文件夾 "backups"，使用 fastRetry(3)），並清空初始化時的請求記錄
+// transport 為 nil 時使用模擬服務的默認傳輸；configure 用於修改默認配置（可為 nil）
+func newTestClient(t testing.TB, srv *gdrivetest.Server, transport http.RoundTripper, configure func(*gdrive.Config)) *gdrive.Client {
+	t.Helper()
+
+	config := &gdrive.Config{
+		FolderName: "backups",
+		Retry:      fastRetry(3),
+		Logger:     testLogger{t},
+	}
+	if configure != nil {
+		configure(config)
+	}
+
+	var opts []gdrive.Option
+	if transport != nil {
+		opts = append(opts, gdrive.WithHTTPClient(&http.Client{Transport: transport}))
+	}
+	client, err := srv.NewClient(config, opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv.ResetRequests()
+	return client
+}
@@ -0,0 +1,91 @@
+package gdrivetest_test
+
+import (
+	"bytes"
+	"crypto/rand"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Digman/gdrive"
+	"github.com/Digman/gdrive/gdrivetest"
+)
+
+// testLogger 將日志輸出到 t.Logf
+type testLogger struct {
+	t testing.TB
+}
+
+func (l testLogger) Infof(format string, v ...interface{})    { l.t.Logf(format, v...) }
+func (l testLogger) Warningf(format string, v ...interface{}) { l.t.Logf(format, v...) }
+func (l testLogger) Errorf(format string, v ...interface{})   { l.t.Logf(format, v...) }
+
+func TestClientRoundTrip(t *testing.T) {
+	tests := []struct {
+		name string
+		size int
+	}{
+		{"multipart", 1 << 10},
+		{"resumable", 600 << 10}, // 大於分塊大小，使用可續傳上傳
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := gdrivetest.NewServer()
+			defer srv.Close()
+
+			client, err := srv.NewClient(&gdrive.Config{
+				FolderName:      "backups/db",
+				UploadChunkSize: 256 << 10,
+				Logger:          testLogger{t},
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			content := make([]byte, tt.size)
+			if _, err := rand.Read(content); err != nil {
+				t.Fatal(err)
+			}
+			localPath := filepath.Join(t.TempDir(), "dump.sql")
+			if err := os.WriteFile(localPath, content, 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			fileID, err := client.UploadFile(localPath)
+			if err != nil {
+				t.Fatalf("UploadFile: %v", err)
+			}
+
+			files, err := client.FindFiles(gdrive.NewQuery().Name("dump.sql").InParents(client.GetFolderID()))
+			if err != nil {
+				t.Fatalf("FindFiles: %v", err)
+			}
+			if len(files) != 1 || files[0].ID != fileID {
+				t.Fatalf("FindFiles = %+v, want %s", files, fileID)
+			}
+			if files[0].Size != int64(tt.size) {
+				t.Fatalf("Size = %d, want %d", files[0].Size, tt.size)
+			}
+
+			stored, _ := srv.File(fileID)
+			if files[0].MD5Checksum != stored.MD5() {
+				t.Fatalf("MD5Checksum = %s, want %s", files[0].MD5Checksum, stored.MD5())
+			}
+
+			var buf bytes.Buffer
+			if err := client.DownloadTo(fileID, &buf); err != nil {
+				t.Fatalf("DownloadTo: %v", err)
+			}
+			if !bytes.Equal(buf.Bytes(), content) {
+				t.Fatal("downloaded content differs from uploaded content")
+			}
+
+			// 文件夾路徑逐級創建
+			folders := srv.FindByName("db")
+			if len(folders) != 1 || !folders[0].IsFolder() || folders[0].ID != client.GetFolderID() {
+				t.Fatalf("folder db = %+v", folders)
+			}
+		})
+	}
+}
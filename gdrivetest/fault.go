@@ -0,0 +1,114 @@
+package gdrivetest
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// Fault 錯誤注入規則，匹配的請求直接返回指定錯誤而不做處理
+type Fault struct {
+	Method     string // 匹配的 HTTP 方法，為空表示任意方法
+	Path       string // 匹配的路徑前綴（如 "/upload/drive/v3/files"），為空表示任意路徑
+	Status     int    // 返回的 HTTP 狀態碼
+	Reason     string // 錯誤原因（如 "userRateLimitExceeded"），為空時根據狀態碼推斷
+	Message    string // 錯誤信息，為空時使用默認信息
+	RetryAfter int    // Retry-After 響應頭（秒），0 表示不設置
+	Times      int    // 生效次數，0 表示一直生效
+
+	// AfterHandling 為 true 時先正常處理請求再返回錯誤，模擬服務端已處理但響應丟失（如創建成功後返回 503）
+	AfterHandling bool
+}
+
+// 常用錯誤注入規則
+var (
+	// RateLimitExceeded 403 userRateLimitExceeded
+	RateLimitExceeded = Fault{Status: http.StatusForbidden, Reason: "userRateLimitExceeded"}
+
+	// TooManyRequests 429 rateLimitExceeded
+	TooManyRequests = Fault{Status: http.StatusTooManyRequests, Reason: "rateLimitExceeded"}
+
+	// InternalError 500 internalError
+	InternalError = Fault{Status: http.StatusInternalServerError, Reason: "internalError"}
+
+	// ServiceUnavailable 503 backendError
+	ServiceUnavailable = Fault{Status: http.StatusServiceUnavailable, Reason: "backendError"}
+)
+
+// InjectFault 添加錯誤注入規則，按添加順序匹配
+func (s *Server) InjectFault(fault Fault) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	f := fault
+	s.faults = append(s.faults, &f)
+}
+
+// FailNext 使接下來的 n 個請求（任意方法和路徑）返回指定錯誤
+func (s *Server) FailNext(n int, fault Fault) {
+	fault.Method = ""
+	fault.Path = ""
+	fault.Times = n
+	s.InjectFault(fault)
+}
+
+// ClearFaults 清除所有錯誤注入規則
+func (s *Server) ClearFaults() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.faults = nil
+}
+
+// matchFaultLocked 查找與請求匹配的錯誤注入規則並扣減剩餘次數
+func (s *Server) matchFaultLocked(r *http.Request) *Fault {
+	for i, f := range s.faults {
+		if f.Method != "" && !strings.EqualFold(f.Method, r.Method) {
+			continue
+		}
+		if f.Path != "" && !strings.HasPrefix(r.URL.Path, f.Path) {
+			continue
+		}
+
+		matched := *f
+		if f.Times > 0 {
+			f.Times--
+			if f.Times == 0 {
+				s.faults = append(s.faults[:i:i], s.faults[i+1:]...)
+			}
+		}
+		return &matched
+	}
+	return nil
+}
+
+// write 輸出錯誤響應
+func (f *Fault) write(w http.ResponseWriter) {
+	reason := f.Reason
+	if reason == "" {
+		reason = defaultReason(f.Status)
+	}
+	message := f.Message
+	if message == "" {
+		message = http.StatusText(f.Status)
+	}
+	if f.RetryAfter > 0 {
+		w.Header().Set("Retry-After", strconv.Itoa(f.RetryAfter))
+	}
+	writeError(w, f.Status, reason, message)
+}
+
+// defaultReason 根據狀態碼推斷錯誤原因
+func defaultReason(status int) string {
+	switch {
+	case status == http.StatusTooManyRequests:
+		return "rateLimitExceeded"
+	case status == http.StatusForbidden:
+		return "userRateLimitExceeded"
+	case status == http.StatusNotFound:
+		return "notFound"
+	case status >= 500:
+		return "backendError"
+	default:
+		return "badRequest"
+	}
+}
@@ -0,0 +1,393 @@
+package gdrivetest
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// 支持的查詢語法（Drive v3 q 參數的子集）：
+//
+//	name = 'x' / name != 'x' / name contains 'x'
+//	mimeType = 'x' / mimeType != 'x'
+//	'id' in parents
+//	trashed = true|false
+//	modifiedTime > '2006-01-02T15:04:05Z'（同時支持 <、>=、<=、=，createdTime 同理）
+//	appProperties has { key='k' and value='v' }
+//	and / or / not / 括號
+
+// queryFunc 編譯後的查詢條件
+type queryFunc func(f *File) bool
+
+// tokenKind 詞法單元類型
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenIdent
+	tokenString
+	tokenOp
+	tokenLParen
+	tokenRParen
+	tokenLBrace
+	tokenRBrace
+)
+
+// token 詞法單元
+type token struct {
+	kind  tokenKind
+	value string
+}
+
+// tokenize 將查詢字符串拆分為詞法單元
+func tokenize(query string) ([]token, error) {
+	var tokens []token
+	runes := []rune(query)
+
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '(':
+			tokens = append(tokens, token{kind: tokenLParen, value: "("})
+			i++
+		case r == ')':
+			tokens = append(tokens, token{kind: tokenRParen, value: ")"})
+			i++
+		case r == '{':
+			tokens = append(tokens, token{kind: tokenLBrace, value: "{"})
+			i++
+		case r == '}':
+			tokens = append(tokens, token{kind: tokenRBrace, value: "}"})
+			i++
+		case r == '\'':
+			// 字符串字面量，支持 \' 和 \\ 轉義
+			var sb strings.Builder
+			i++
+			closed := false
+			for i < len(runes) {
+				if runes[i] == '\\' && i+1 < len(runes) {
+					sb.WriteRune(runes[i+1])
+					i += 2
+					continue
+				}
+				if runes[i] == '\'' {
+					closed = true
+					i++
+					break
+				}
+				sb.WriteRune(runes[i])
+				i++
+			}
+			if !closed {
+				return nil, fmt.Errorf("unterminated string literal")
+			}
+			tokens = append(tokens, token{kind: tokenString, value: sb.String()})
+		case strings.ContainsRune("=!<>", r):
+			op := string(r)
+			if i+1 < len(runes) && runes[i+1] == '=' {
+				op += "="
+				i++
+			}
+			if op == "!" {
+				return nil, fmt.Errorf("invalid operator %q", op)
+			}
+			tokens = append(tokens, token{kind: tokenOp, value: op})
+			i++
+		case unicode.IsLetter(r):
+			start := i
+			for i < len(runes) && (unicode.IsLetter(runes[i]) || unicode.IsDigit(runes[i]) || runes[i] == '.' || runes[i] == '_') {
+				i++
+			}
+			tokens = append(tokens, token{kind: tokenIdent, value: string(runes[start:i])})
+		default:
+			return nil, fmt.Errorf("unexpected character %q", r)
+		}
+	}
+
+	return append(tokens, token{kind: tokenEOF}), nil
+}
+
+// queryParser 遞歸下降查詢解析器
+type queryParser struct {
+	tokens []token
+	pos    int
+}
+
+// parseQuery 解析查詢字符串；空查詢匹配所有文件
+func parseQuery(query string) (queryFunc, error) {
+	if strings.TrimSpace(query) == "" {
+		return func(*File) bool { return true }, nil
+	}
+
+	tokens, err := tokenize(query)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &queryParser{tokens: tokens}
+	fn, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.peek().kind != tokenEOF {
+		return nil, fmt.Errorf("unexpected token %q", p.peek().value)
+	}
+	return fn, nil
+}
+
+func (p *queryParser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *queryParser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != tokenEOF {
+		p.pos++
+	}
+	return t
+}
+
+// isKeyword 判斷下一個詞法單元是否為指定關鍵字（不區分大小寫）
+func (p *queryParser) isKeyword(keyword string) bool {
+	t := p.peek()
+	return t.kind == tokenIdent && strings.EqualFold(t.value, keyword)
+}
+
+func (p *queryParser) expect(kind tokenKind, value string) error {
+	t := p.next()
+	if t.kind != kind || (value != "" && !strings.EqualFold(t.value, value)) {
+		return fmt.Errorf("expected %q, got %q", value, t.value)
+	}
+	return nil
+}
+
+func (p *queryParser) parseOr() (queryFunc, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.isKeyword("or") {
+		p.next()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		l, r := left, right
+		left = func(f *File) bool { return l(f) || r(f) }
+	}
+	return left, nil
+}
+
+func (p *queryParser) parseAnd() (queryFunc, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.isKeyword("and") {
+		p.next()
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		l, r := left, right
+		left = func(f *File) bool { return l(f) && r(f) }
+	}
+	return left, nil
+}
+
+func (p *queryParser) parseUnary() (queryFunc, error) {
+	if p.isKeyword("not") {
+		p.next()
+		inner, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return func(f *File) bool { return !inner(f) }, nil
+	}
+
+	if p.peek().kind == tokenLParen {
+		p.next()
+		inner, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(tokenRParen, ")"); err != nil {
+			return nil, err
+		}
+		return inner, nil
+	}
+
+	return p.parseTerm()
+}
+
+func (p *queryParser) parseTerm() (queryFunc, error) {
+	t := p.next()
+
+	// 'id' in parents
+	if t.kind == tokenString {
+		if err := p.expect(tokenIdent, "in"); err != nil {
+			return nil, err
+		}
+		if err := p.expect(tokenIdent, "parents"); err != nil {
+			return nil, err
+		}
+		parentID := t.value
+		return func(f *File) bool { return containsString(f.Parents, parentID) }, nil
+	}
+
+	if t.kind != tokenIdent {
+		return nil, fmt.Errorf("unexpected token %q", t.value)
+	}
+
+	field := t.value
+	switch field {
+	case "appProperties", "properties":
+		return p.parseHas(field)
+	}
+
+	opToken := p.next()
+	op := opToken.value
+	if opToken.kind == tokenIdent && strings.EqualFold(op, "contains") {
+		op = "contains"
+	} else if opToken.kind != tokenOp {
+		return nil, fmt.Errorf("expected operator after %s, got %q", field, op)
+	}
+
+	value := p.next()
+	switch field {
+	case "name", "mimeType", "fullText", "driveId":
+		if value.kind != tokenString {
+			return nil, fmt.Errorf("%s expects a string value", field)
+		}
+		return stringCondition(field, op, value.value)
+	case "trashed":
+		if value.kind != tokenIdent || (value.value != "true" && value.value != "false") {
+			return nil, fmt.Errorf("%s expects true or false", field)
+		}
+		want := value.value == "true"
+		if op != "=" && op != "!=" {
+			return nil, fmt.Errorf("unsupported operator %s for %s", op, field)
+		}
+		return func(f *File) bool { return (f.Trashed == want) == (op == "=") }, nil
+	case "modifiedTime", "createdTime":
+		if value.kind != tokenString {
+			return nil, fmt.Errorf("%s expects an RFC 3339 string", field)
+		}
+		ts, err := time.Parse(time.RFC3339, value.value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid time %q: %w", value.value, err)
+		}
+		return timeCondition(field, op, ts)
+	default:
+		return nil, fmt.Errorf("unsupported query field %q", field)
+	}
+}
+
+// parseHas 解析 appProperties has { key='k' and value='v' }
+func (p *queryParser) parseHas(field string) (queryFunc, error) {
+	if err := p.expect(tokenIdent, "has"); err != nil {
+		return nil, err
+	}
+	if err := p.expect(tokenLBrace, "{"); err != nil {
+		return nil, err
+	}
+
+	pair := map[string]string{}
+	for i := 0; i < 2; i++ {
+		name := p.next()
+		if name.kind != tokenIdent || (name.value != "key" && name.value != "value") {
+			return nil, fmt.Errorf("expected key or value, got %q", name.value)
+		}
+		if err := p.expect(tokenOp, "="); err != nil {
+			return nil, err
+		}
+		value := p.next()
+		if value.kind != tokenString {
+			return nil, fmt.Errorf("%s expects a string value", name.value)
+		}
+		pair[name.value] = value.value
+		if i == 0 {
+			if err := p.expect(tokenIdent, "and"); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if err := p.expect(tokenRBrace, "}"); err != nil {
+		return nil, err
+	}
+
+	key, value := pair["key"], pair["value"]
+	return func(f *File) bool {
+		props := f.AppProperties
+		if field == "properties" {
+			props = f.Properties
+		}
+		got, ok := props[key]
+		return ok && got == value
+	}, nil
+}
+
+// stringCondition 構建字符串字段條件
+func stringCondition(field, op, value string) (queryFunc, error) {
+	get := func(f *File) string {
+		switch field {
+		case "name", "fullText":
+			return f.Name
+		case "mimeType":
+			return f.MimeType
+		default:
+			return f.DriveID
+		}
+	}
+
+	switch op {
+	case "=":
+		return func(f *File) bool { return get(f) == value }, nil
+	case "!=":
+		return func(f *File) bool { return get(f) != value }, nil
+	case "contains":
+		return func(f *File) bool { return strings.Contains(get(f), value) }, nil
+	default:
+		return nil, fmt.Errorf("unsupported operator %s for %s", op, field)
+	}
+}
+
+// timeCondition 構建時間字段條件
+func timeCondition(field, op string, value time.Time) (queryFunc, error) {
+	get := func(f *File) time.Time {
+		if field == "createdTime" {
+			return f.CreatedTime
+		}
+		return f.ModifiedTime
+	}
+
+	switch op {
+	case "=":
+		return func(f *File) bool { return get(f).Equal(value) }, nil
+	case "!=":
+		return func(f *File) bool { return !get(f).Equal(value) }, nil
+	case ">":
+		return func(f *File) bool { return get(f).After(value) }, nil
+	case ">=":
+		return func(f *File) bool { return !get(f).Before(value) }, nil
+	case "<":
+		return func(f *File) bool { return get(f).Before(value) }, nil
+	case "<=":
+		return func(f *File) bool { return !get(f).After(value) }, nil
+	default:
+		return nil, fmt.Errorf("unsupported operator %s for %s", op, field)
+	}
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,142 @@
+package gdrivetest
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTokenize(t *testing.T) {
+	tests := []struct {
+		query string
+		want  []token
+	}{
+		{`name = 'a'`, []token{{tokenIdent, "name"}, {tokenOp, "="}, {tokenString, "a"}}},
+		{`name != 'O\'Brien'`, []token{{tokenIdent, "name"}, {tokenOp, "!="}, {tokenString, "O'Brien"}}},
+		{`name = 'C:\\tmp'`, []token{{tokenIdent, "name"}, {tokenOp, "="}, {tokenString, `C:\tmp`}}},
+		{`name = '\\\''`, []token{{tokenIdent, "name"}, {tokenOp, "="}, {tokenString, `\'`}}},
+		{`name = ''`, []token{{tokenIdent, "name"}, {tokenOp, "="}, {tokenString, ""}}},
+		{`modifiedTime>='x'`, []token{{tokenIdent, "modifiedTime"}, {tokenOp, ">="}, {tokenString, "x"}}},
+		{`(a){b}`, []token{{tokenLParen, "("}, {tokenIdent, "a"}, {tokenRParen, ")"}, {tokenLBrace, "{"}, {tokenIdent, "b"}, {tokenRBrace, "}"}}},
+		{`name = '備份 1'`, []token{{tokenIdent, "name"}, {tokenOp, "="}, {tokenString, "備份 1"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			got, err := tokenize(tt.query)
+			if err != nil {
+				t.Fatalf("tokenize: %v", err)
+			}
+			want := append(tt.want, token{kind: tokenEOF})
+			if len(got) != len(want) {
+				t.Fatalf("tokenize = %v, want %v", got, want)
+			}
+			for i := range want {
+				if got[i] != want[i] {
+					t.Fatalf("token[%d] = %v, want %v", i, got[i], want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestParseQuery(t *testing.T) {
+	base := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	files := []*File{
+		{ID: "1", Name: "report.txt", MimeType: "text/plain", Parents: []string{"root"}, CreatedTime: base, ModifiedTime: base},
+		{ID: "2", Name: "O'Brien.txt", MimeType: "text/plain", Parents: []string{"folder"}, CreatedTime: base, ModifiedTime: base.Add(time.Hour)},
+		{ID: "3", Name: `C:\tmp`, MimeType: FolderMimeType, Parents: []string{"root"}, CreatedTime: base, ModifiedTime: base.Add(-time.Hour)},
+		{ID: "4", Name: "old.txt", MimeType: "text/plain", Parents: []string{"folder"}, Trashed: true, AppProperties: map[string]string{"sha256": "abc"}},
+		{ID: "5", Name: "props.txt", MimeType: "text/plain", Parents: []string{"folder"}, Properties: map[string]string{"sha256": "abc"}},
+	}
+
+	tests := []struct {
+		query string
+		want  string // 匹配的文件 ID
+	}{
+		{``, "12345"},
+		{`name = 'report.txt'`, "1"},
+		{`name = 'O\'Brien.txt'`, "2"},
+		{`name = 'C:\\tmp'`, "3"},
+		{`name != 'report.txt'`, "2345"},
+		{`name contains '.txt'`, "1245"},
+		{`name contains 'Brien'`, "2"},
+		{`mimeType = 'application/vnd.google-apps.folder'`, "3"},
+		{`mimeType != 'application/vnd.google-apps.folder'`, "1245"},
+		{`'folder' in parents`, "245"},
+		{`'root' in parents and name contains '.txt'`, "1"},
+		{`trashed = true`, "4"},
+		{`trashed = false`, "1235"},
+		{`trashed != true`, "1235"},
+		{`modifiedTime > '2024-01-02T03:04:05Z'`, "2"},
+		{`modifiedTime >= '2024-01-02T03:04:05Z'`, "12"},
+		{`modifiedTime < '2024-01-02T03:04:05Z'`, "345"},
+		{`modifiedTime <= '2024-01-02T03:04:05Z'`, "1345"},
+		{`modifiedTime = '2024-01-02T03:04:05Z'`, "1"},
+		{`createdTime = '2024-01-02T03:04:05Z'`, "123"},
+		{`appProperties has { key='sha256' and value='abc' }`, "4"},
+		{`appProperties has { value='abc' and key='sha256' }`, "4"},
+		{`appProperties has { key='sha256' and value='def' }`, ""},
+		{`properties has { key='sha256' and value='abc' }`, "5"},
+		{`not trashed = true`, "1235"},
+		{`name = 'report.txt' or name = 'old.txt'`, "14"},
+		// and 優先於 or
+		{`name = 'report.txt' or 'folder' in parents and trashed = false`, "125"},
+		{`(name = 'report.txt' or 'folder' in parents) and trashed = false`, "125"},
+		{`(name = 'report.txt' or name = 'old.txt') and trashed = false`, "1"},
+		{`not (trashed = true or 'root' in parents)`, "25"},
+		{`name = 'x' OR name = 'report.txt'`, "1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			match, err := parseQuery(tt.query)
+			if err != nil {
+				t.Fatalf("parseQuery: %v", err)
+			}
+			got := ""
+			for _, f := range files {
+				if match(f) {
+					got += f.ID
+				}
+			}
+			if got != tt.want {
+				t.Fatalf("matched %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseQueryErrors(t *testing.T) {
+	tests := []string{
+		`name = 'unterminated`,
+		`name = 'escaped end\'`,
+		`name ! 'x'`,
+		`name ~ 'x'`,
+		`name = x`,
+		`name 'x'`,
+		`size > '10'`,
+		`NAME = 'x'`, // 字段名區分大小寫
+		`trashed = 'true'`,
+		`trashed > true`,
+		`modifiedTime > 'yesterday'`,
+		`modifiedTime contains '2024-01-02T03:04:05Z'`,
+		`mimeType > 'text/plain'`,
+		`'root' in`,
+		`'root' in folders`,
+		`(name = 'x'`,
+		`name = 'x')`,
+		`name = 'x' and`,
+		`appProperties has { key='k' }`,
+		`appProperties has { key='k' and value=v }`,
+		`appProperties has { key='k' and value='v'`,
+		`appProperties = 'k'`,
+	}
+
+	for _, query := range tests {
+		t.Run(query, func(t *testing.T) {
+			if _, err := parseQuery(query); err == nil {
+				t.Fatalf("parseQuery(%q) should fail", query)
+			}
+		})
+	}
+}
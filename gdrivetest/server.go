@@ -0,0 +1,1141 @@
+// Package gdrivetest 提供內存中的 Google Drive v3 模擬服務，用於離線測試基於 gdrive 的備份邏輯
+//
+// 支持 files.create/update/list/get/delete、multipart 和 resumable 上傳、
+// gdrive 使用的 q 查詢子集、about.get，以及 403/429/5xx 等錯誤注入。
+package gdrivetest
+
+import (
+	"bytes"
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Digman/gdrive"
+	"golang.org/x/oauth2"
+)
+
+const (
+	// FolderMimeType 文件夾的 MIME 類型
+	FolderMimeType = "application/vnd.google-apps.folder"
+
+	// RootID 根目錄 ID（未指定父級的文件會放在根目錄下）
+	RootID = "root"
+
+	// AppDataFolderID 應用數據文件夾 ID
+	AppDataFolderID = "appDataFolder"
+
+	// AccessToken 模擬服務接受的 Access Token
+	AccessToken = "gdrivetest-token"
+)
+
+// File 模擬服務中保存的文件
+type File struct {
+	ID            string
+	Name          string
+	MimeType      string
+	Description   string
+	Parents       []string
+	AppProperties map[string]string
+	Properties    map[string]string
+	Trashed       bool
+	DriveID       string // 所屬共享雲端硬碟 ID（為空表示在“我的雲端硬碟”中）
+	CreatedTime   time.Time
+	ModifiedTime  time.Time
+	Content       []byte
+}
+
+// MD5 返回文件內容的 MD5 十六進制字符串
+func (f *File) MD5() string {
+	sum := md5.Sum(f.Content)
+	return hex.EncodeToString(sum[:])
+}
+
+// IsFolder 判斷是否為文件夾
+func (f *File) IsFolder() bool {
+	return f.MimeType == FolderMimeType
+}
+
+// clone 返回文件的深拷貝
+func (f *File) clone() File {
+	c := *f
+	c.Parents = append([]string(nil), f.Parents...)
+	c.AppProperties = cloneMap(f.AppProperties)
+	c.Properties = cloneMap(f.Properties)
+	c.Content = append([]byte(nil), f.Content...)
+	return c
+}
+
+// Request 模擬服務收到的請求記錄
+type Request struct {
+	Method string
+	Path   string
+	Query  string
+}
+
+// uploadSession 可續傳上傳會話
+type uploadSession struct {
+	fileID   string          // 更新時的目標文件 ID，創建時為空
+	metadata json.RawMessage // 初始化請求中的文件元數據
+	query    map[string][]string
+	total    int64 // 總大小，-1 表示未知
+	data     []byte
+	done     string // 上傳完成後的文件 ID
+}
+
+// Server 內存中的 Google Drive v3 模擬服務
+type Server struct {
+	srv *httptest.Server
+
+	mu       sync.Mutex
+	files    map[string]*File
+	order    []string // 文件創建順序
+	sessions map[string]*uploadSession
+	faults   []*Fault
+	requests []Request
+	nextID   int
+	now      func() time.Time
+
+	user       User
+	quotaLimit int64 // 存儲空間上限，0 表示不限制
+	drives     []SharedDrive
+}
+
+// User 模擬的賬號信息
+type User struct {
+	DisplayName  string
+	EmailAddress string
+}
+
+// SharedDrive 模擬的共享雲端硬碟
+type SharedDrive struct {
+	ID   string
+	Name string
+}
+
+// NewServer 創建並啟動模擬服務
+func NewServer() *Server {
+	s := &Server{
+		files:    make(map[string]*File),
+		sessions: make(map[string]*uploadSession),
+		now:      time.Now,
+		user: User{
+			DisplayName:  "gdrivetest",
+			EmailAddress: "gdrivetest@example.com",
+		},
+	}
+	s.srv = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	return s
+}
+
+// URL 返回模擬服務的根地址
+func (s *Server) URL() string {
+	return s.srv.URL
+}
+
+// HTTPClient 返回訪問模擬服務的 HTTP 客戶端
+func (s *Server) HTTPClient() *http.Client {
+	return s.srv.Client()
+}
+
+// Close 關閉模擬服務
+func (s *Server) Close() {
+	s.srv.Close()
+}
+
+// Options 返回將 gdrive.Client 連接到模擬服務所需的可選配置
+func (s *Server) Options() []gdrive.Option {
+	return []gdrive.Option{
+		gdrive.WithEndpoint(s.srv.URL),
+		gdrive.WithHTTPClient(s.srv.Client()),
+		gdrive.WithTokenSource(oauth2.StaticTokenSource(&oauth2.Token{
+			AccessToken: AccessToken,
+			TokenType:   "Bearer",
+		})),
+	}
+}
+
+// NewClient 創建連接到模擬服務的 gdrive.Client
+// config 為 nil 時使用默認配置（FolderName 為 "gdrivetest"）；未設置 Enabled 時自動啟用
+func (s *Server) NewClient(config *gdrive.Config, opts ...gdrive.Option) (*gdrive.Client, error) {
+	if config == nil {
+		config = &gdrive.Config{FolderName: "gdrivetest"}
+	}
+	config.Enabled = true
+
+	return gdrive.NewClient(config, append(s.Options(), opts...)...)
+}
+
+// SetUser 設置 about.get 返回的賬號信息
+func (s *Server) SetUser(user User) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.user = user
+}
+
+// SetQuota 設置存儲空間上限（字節），0 表示不限制
+// 超出上限的上傳返回 403 storageQuotaExceeded
+func (s *Server) SetQuota(limit int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.quotaLimit = limit
+}
+
+// SetClock 設置模擬服務使用的時鐘（用於控制 createdTime / modifiedTime）
+func (s *Server) SetClock(now func() time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.now = now
+}
+
+// AddSharedDrive 添加共享雲端硬碟，返回其 ID
+// 共享雲端硬碟中的文件只有在請求設置了 supportsAllDrives=true 時才能創建和更新，
+// 且只有在設置了 driveId 或 includeItemsFromAllDrives=true 時才會被列出
+func (s *Server) AddSharedDrive(name string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.nextID++
+	id := fmt.Sprintf("drive-%d", s.nextID)
+	s.drives = append(s.drives, SharedDrive{ID: id, Name: name})
+	return id
+}
+
+// AddFile 直接向模擬服務添加文件（不經過 API），返回文件 ID
+// 未指定 ID 時自動生成；未指定父級時放在根目錄下
+func (s *Server) AddFile(f File) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	file := f.clone()
+	if file.ID == "" {
+		file.ID = s.newIDLocked()
+	}
+	if len(file.Parents) == 0 {
+		file.Parents = []string{RootID}
+	}
+	now := s.now()
+	if file.CreatedTime.IsZero() {
+		file.CreatedTime = now
+	}
+	if file.ModifiedTime.IsZero() {
+		file.ModifiedTime = now
+	}
+	s.putLocked(&file)
+	return file.ID
+}
+
+// File 返回指定 ID 的文件副本
+func (s *Server) File(id string) (File, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	f, ok := s.files[id]
+	if !ok {
+		return File{}, false
+	}
+	return f.clone(), true
+}
+
+// Files 按創建順序返回所有文件的副本
+func (s *Server) Files() []File {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	files := make([]File, 0, len(s.order))
+	for _, id := range s.order {
+		files = append(files, s.files[id].clone())
+	}
+	return files
+}
+
+// FindByName 返回指定名稱的所有文件（不包括已刪除到回收站的文件）
+func (s *Server) FindByName(name string) []File {
+	var matched []File
+	for _, f := range s.Files() {
+		if f.Name == name && !f.Trashed {
+			matched = append(matched, f)
+		}
+	}
+	return matched
+}
+
+// Requests 返回模擬服務收到的所有請求記錄
+func (s *Server) Requests() []Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Request(nil), s.requests...)
+}
+
+// ResetRequests 清空請求記錄
+func (s *Server) ResetRequests() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.requests = nil
+}
+
+// serveHTTP 處理所有請求
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	s.requests = append(s.requests, Request{Method: r.Method, Path: r.URL.Path, Query: r.URL.RawQuery})
+	fault := s.matchFaultLocked(r)
+	s.mu.Unlock()
+
+	if r.Header.Get("Authorization") != "Bearer "+AccessToken {
+		writeError(w, http.StatusUnauthorized, "authError", "Invalid Credentials")
+		return
+	}
+
+	if fault != nil && fault.AfterHandling {
+		// 處理請求但丟棄響應
+		s.handle(httptest.NewRecorder(), r)
+		fault.write(w)
+		return
+	}
+	if fault != nil {
+		// 丟棄請求體，模擬服務端在處理前失敗
+		_, _ = io.Copy(io.Discard, r.Body)
+		fault.write(w)
+		return
+	}
+
+	s.handle(w, r)
+}
+
+// handle 按路徑和方法分發請求
+func (s *Server) handle(w http.ResponseWriter, r *http.Request) {
+	path := r.URL.Path
+	switch {
+	case path == "/drive/v3/about" && r.Method == http.MethodGet:
+		s.handleAbout(w)
+	case path == "/drive/v3/drives" && r.Method == http.MethodGet:
+		s.handleListDrives(w)
+	case path == "/drive/v3/files" && r.Method == http.MethodGet:
+		s.handleList(w, r)
+	case path == "/drive/v3/files" && r.Method == http.MethodPost:
+		s.handleCreate(w, r, nil, nil)
+	case strings.HasPrefix(path, "/drive/v3/files/"):
+		id := strings.TrimPrefix(path, "/drive/v3/files/")
+		switch r.Method {
+		case http.MethodGet:
+			s.handleGet(w, r, id)
+		case http.MethodPatch:
+			s.handleUpdate(w, r, id, nil, nil)
+		case http.MethodDelete:
+			s.handleDelete(w, id)
+		default:
+			writeError(w, http.StatusMethodNotAllowed, "methodNotAllowed", "Method not allowed")
+		}
+	case path == "/upload/drive/v3/files" || strings.HasPrefix(path, "/upload/drive/v3/files/"):
+		s.handleUpload(w, r, strings.TrimPrefix(strings.TrimPrefix(path, "/upload/drive/v3/files"), "/"))
+	default:
+		writeError(w, http.StatusNotFound, "notFound", "Not Found")
+	}
+}
+
+// handleAbout 處理 about.get
+func (s *Server) handleAbout(w http.ResponseWriter) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var usage, trash int64
+	for _, f := range s.files {
+		// 共享雲端硬碟中的文件不計入用戶配額
+		if f.DriveID != "" {
+			continue
+		}
+		usage += int64(len(f.Content))
+		if f.Trashed {
+			trash += int64(len(f.Content))
+		}
+	}
+
+	quota := map[string]interface{}{
+		"usage":             strconv.FormatInt(usage, 10),
+		"usageInDrive":      strconv.FormatInt(usage, 10),
+		"usageInDriveTrash": strconv.FormatInt(trash, 10),
+	}
+	if s.quotaLimit > 0 {
+		quota["limit"] = strconv.FormatInt(s.quotaLimit, 10)
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"kind": "drive#about",
+		"user": map[string]interface{}{
+			"kind":         "drive#user",
+			"displayName":  s.user.DisplayName,
+			"emailAddress": s.user.EmailAddress,
+		},
+		"storageQuota": quota,
+	})
+}
+
+// handleListDrives 處理 drives.list
+func (s *Server) handleListDrives(w http.ResponseWriter) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	drives := make([]map[string]interface{}, 0, len(s.drives))
+	for _, d := range s.drives {
+		drives = append(drives, map[string]interface{}{"kind": "drive#drive", "id": d.ID, "name": d.Name})
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"kind": "drive#driveList", "drives": drives})
+}
+
+// handleList 處理 files.list
+func (s *Server) handleList(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	match, err := parseQuery(query.Get("q"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid", "Invalid Value: "+err.Error())
+		return
+	}
+
+	spaces := query.Get("spaces")
+	if spaces == "" {
+		spaces = "drive"
+	}
+	driveID := query.Get("driveId")
+	allDrives := query.Get("includeItemsFromAllDrives") == "true"
+	if driveID != "" && (query.Get("corpora") != "drive" || !allDrives || query.Get("supportsAllDrives") != "true") {
+		writeError(w, http.StatusBadRequest, "invalid", "driveId requires corpora=drive, includeItemsFromAllDrives=true and supportsAllDrives=true")
+		return
+	}
+
+	s.mu.Lock()
+	var matched []*File
+	for _, id := range s.order {
+		f := s.files[id]
+		if !s.inSpaceLocked(f, spaces) {
+			continue
+		}
+		// 共享雲端硬碟中的文件僅在指定 driveId 或 includeItemsFromAllDrives 時返回
+		if driveID != "" && f.DriveID != driveID {
+			continue
+		}
+		if driveID == "" && f.DriveID != "" && !allDrives {
+			continue
+		}
+		if match(f) {
+			matched = append(matched, f)
+		}
+	}
+	s.mu.Unlock()
+
+	// 分頁
+	offset, _ := strconv.Atoi(query.Get("pageToken"))
+	pageSize, _ := strconv.Atoi(query.Get("pageSize"))
+	if pageSize <= 0 {
+		pageSize = 100
+	}
+	if offset > len(matched) {
+		offset = len(matched)
+	}
+	end := offset + pageSize
+	if end > len(matched) {
+		end = len(matched)
+	}
+
+	files := make([]map[string]interface{}, 0, end-offset)
+	for _, f := range matched[offset:end] {
+		files = append(files, fileJSON(f))
+	}
+
+	resp := map[string]interface{}{
+		"kind":             "drive#fileList",
+		"incompleteSearch": false,
+		"files":            files,
+	}
+	if end < len(matched) {
+		resp["nextPageToken"] = strconv.Itoa(end)
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// handleGet 處理 files.get（alt=media 時返回文件內容，支持 Range 請求）
+func (s *Server) handleGet(w http.ResponseWriter, r *http.Request, id string) {
+	s.mu.Lock()
+	f, ok := s.files[id]
+	var file File
+	if ok {
+		file = f.clone()
+	}
+	s.mu.Unlock()
+
+	if !ok || !allDrivesVisible(&file, r) {
+		writeError(w, http.StatusNotFound, "notFound", "File not found: "+id+".")
+		return
+	}
+
+	if r.URL.Query().Get("alt") == "media" {
+		if file.IsFolder() {
+			writeError(w, http.StatusForbidden, "fileNotDownloadable", "Only files with binary content can be downloaded.")
+			return
+		}
+		w.Header().Set("Content-Type", mimeTypeOrDefault(file.MimeType))
+		http.ServeContent(w, r, "", file.ModifiedTime, bytes.NewReader(file.Content))
+		return
+	}
+
+	writeJSON(w, http.StatusOK, fileJSON(&file))
+}
+
+// handleDelete 處理 files.delete
+func (s *Server) handleDelete(w http.ResponseWriter, id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.files[id]; !ok {
+		writeError(w, http.StatusNotFound, "notFound", "File not found: "+id+".")
+		return
+	}
+	s.deleteLocked(id)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleCreate 處理 files.create（content 為 nil 表示僅創建元數據）
+func (s *Server) handleCreate(w http.ResponseWriter, r *http.Request, metadata []byte, content []byte) {
+	if metadata == nil {
+		var err error
+		if metadata, err = io.ReadAll(r.Body); err != nil {
+			writeError(w, http.StatusBadRequest, "badRequest", err.Error())
+			return
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	file := &File{}
+	if err := applyMetadata(file, metadata); err != nil {
+		writeError(w, http.StatusBadRequest, "parseError", "Parse Error: "+err.Error())
+		return
+	}
+	if file.MimeType == "" && content != nil {
+		file.MimeType = "application/octet-stream"
+	}
+
+	if err := s.resolveParentsLocked(file); err != nil || !allDrivesVisible(file, r) {
+		writeError(w, http.StatusNotFound, "notFound", "File not found: "+strings.Join(file.Parents, ",")+".")
+		return
+	}
+	if content != nil {
+		if file.DriveID == "" && s.exceedsQuotaLocked(int64(len(content))) {
+			writeError(w, http.StatusForbidden, "storageQuotaExceeded", "The user's Drive storage quota has been exceeded.")
+			return
+		}
+		file.Content = content
+	}
+
+	now := s.now()
+	file.ID = s.newIDLocked()
+	file.CreatedTime = now
+	if file.ModifiedTime.IsZero() {
+		file.ModifiedTime = now
+	}
+	s.putLocked(file)
+
+	writeJSON(w, http.StatusOK, fileJSON(file))
+}
+
+// handleUpdate 處理 files.update（content 為 nil 表示僅更新元數據）
+func (s *Server) handleUpdate(w http.ResponseWriter, r *http.Request, id string, metadata []byte, content []byte) {
+	if metadata == nil {
+		var err error
+		if metadata, err = io.ReadAll(r.Body); err != nil {
+			writeError(w, http.StatusBadRequest, "badRequest", err.Error())
+			return
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	file, ok := s.files[id]
+	if !ok || !allDrivesVisible(file, r) {
+		writeError(w, http.StatusNotFound, "notFound", "File not found: "+id+".")
+		return
+	}
+
+	updated := file.clone()
+	if err := applyMetadata(&updated, metadata); err != nil {
+		writeError(w, http.StatusBadRequest, "parseError", "Parse Error: "+err.Error())
+		return
+	}
+
+	query := r.URL.Query()
+	for _, parent := range splitList(query.Get("removeParents")) {
+		updated.Parents = removeString(updated.Parents, parent)
+	}
+	for _, parent := range splitList(query.Get("addParents")) {
+		if _, ok := s.files[parent]; !ok && parent != RootID && parent != AppDataFolderID && !s.isDriveLocked(parent) {
+			writeError(w, http.StatusNotFound, "notFound", "File not found: "+parent+".")
+			return
+		}
+		if !containsString(updated.Parents, parent) {
+			updated.Parents = append(updated.Parents, parent)
+		}
+	}
+
+	if content != nil {
+		if file.DriveID == "" && s.exceedsQuotaLocked(int64(len(content))-int64(len(file.Content))) {
+			writeError(w, http.StatusForbidden, "storageQuotaExceeded", "The user's Drive storage quota has been exceeded.")
+			return
+		}
+		updated.Content = content
+	}
+	if !hasField(metadata, "modifiedTime") {
+		updated.ModifiedTime = s.now()
+	}
+
+	*file = updated
+	writeJSON(w, http.StatusOK, fileJSON(file))
+}
+
+// handleUpload 處理 /upload/drive/v3/files 下的 media、multipart 和 resumable 上傳
+func (s *Server) handleUpload(w http.ResponseWriter, r *http.Request, id string) {
+	query := r.URL.Query()
+
+	// 可續傳上傳的後續請求
+	if uploadID := query.Get("upload_id"); uploadID != "" {
+		s.handleResumableChunk(w, r, uploadID)
+		return
+	}
+
+	if (id == "" && r.Method != http.MethodPost) || (id != "" && r.Method != http.MethodPatch) {
+		writeError(w, http.StatusMethodNotAllowed, "methodNotAllowed", "Method not allowed")
+		return
+	}
+
+	switch query.Get("uploadType") {
+	case "media":
+		content, err := io.ReadAll(r.Body)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "badRequest", err.Error())
+			return
+		}
+		s.finishUpload(w, r, id, []byte("{}"), content)
+	case "multipart":
+		metadata, content, err := readMultipart(r)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "badRequest", err.Error())
+			return
+		}
+		s.finishUpload(w, r, id, metadata, content)
+	case "resumable":
+		s.startResumable(w, r, id)
+	default:
+		writeError(w, http.StatusBadRequest, "invalid", "Invalid uploadType: "+query.Get("uploadType"))
+	}
+}
+
+// finishUpload 完成上傳，創建或更新文件
+func (s *Server) finishUpload(w http.ResponseWriter, r *http.Request, id string, metadata, content []byte) {
+	if content == nil {
+		content = []byte{}
+	}
+	if id == "" {
+		s.handleCreate(w, r, metadata, content)
+	} else {
+		s.handleUpdate(w, r, id, metadata, content)
+	}
+}
+
+// startResumable 初始化可續傳上傳會話，通過 Location 頭返回會話地址
+func (s *Server) startResumable(w http.ResponseWriter, r *http.Request, id string) {
+	metadata, err := io.ReadAll(r.Body)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "badRequest", err.Error())
+		return
+	}
+	if len(bytes.TrimSpace(metadata)) == 0 {
+		metadata = []byte("{}")
+	}
+
+	total := int64(-1)
+	if v := r.Header.Get("X-Upload-Content-Length"); v != "" {
+		if total, err = strconv.ParseInt(v, 10, 64); err != nil {
+			writeError(w, http.StatusBadRequest, "badRequest", "Invalid X-Upload-Content-Length")
+			return
+		}
+	}
+
+	s.mu.Lock()
+	if id != "" {
+		if _, ok := s.files[id]; !ok {
+			s.mu.Unlock()
+			writeError(w, http.StatusNotFound, "notFound", "File not found: "+id+".")
+			return
+		}
+	}
+	s.nextID++
+	uploadID := fmt.Sprintf("upload-%d", s.nextID)
+	s.sessions[uploadID] = &uploadSession{
+		fileID:   id,
+		metadata: metadata,
+		query:    r.URL.Query(),
+		total:    total,
+	}
+	s.mu.Unlock()
+
+	location := s.srv.URL + r.URL.Path + "?uploadType=resumable&upload_id=" + uploadID
+	w.Header().Set("Location", location)
+	w.WriteHeader(http.StatusOK)
+}
+
+// handleResumableChunk 處理可續傳上傳的分塊請求和狀態查詢
+func (s *Server) handleResumableChunk(w http.ResponseWriter, r *http.Request, uploadID string) {
+	if r.Method != http.MethodPut && r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, "methodNotAllowed", "Method not allowed")
+		return
+	}
+
+	data, err := io.ReadAll(r.Body)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "badRequest", err.Error())
+		return
+	}
+
+	s.mu.Lock()
+	session, ok := s.sessions[uploadID]
+	if !ok {
+		s.mu.Unlock()
+		writeError(w, http.StatusNotFound, "notFound", "Upload session not found or expired")
+		return
+	}
+	if session.done != "" {
+		// 已完成的會話再次收到請求時返回上傳結果（如客戶端未收到最後一個分塊的響應）
+		f, ok := s.files[session.done]
+		s.mu.Unlock()
+		if !ok {
+			writeError(w, http.StatusNotFound, "notFound", "File not found: "+session.done+".")
+			return
+		}
+		writeJSON(w, http.StatusOK, fileJSON(f))
+		return
+	}
+
+	start, end, total, err := parseContentRange(r.Header.Get("Content-Range"), len(data))
+	if err != nil {
+		s.mu.Unlock()
+		writeError(w, http.StatusBadRequest, "badRequest", err.Error())
+		return
+	}
+	if total >= 0 {
+		session.total = total
+	}
+
+	if start >= 0 {
+		// 只接受從當前偏移開始的數據，重疊部分丟棄
+		received := int64(len(session.data))
+		if start > received {
+			s.mu.Unlock()
+			writeError(w, http.StatusBadRequest, "badRequest", "Content-Range start is beyond the received offset")
+			return
+		}
+		if end >= received {
+			session.data = append(session.data, data[received-start:]...)
+		}
+	}
+
+	received := int64(len(session.data))
+	if session.total < 0 || received < session.total {
+		s.mu.Unlock()
+		if received > 0 {
+			w.Header().Set("Range", fmt.Sprintf("bytes=0-%d", received-1))
+		}
+		// Go 客戶端發送 X-GUploader-No-308 時以 200 加覆蓋頭表示未完成
+		if r.Header.Get("X-GUploader-No-308") == "yes" {
+			w.Header().Set("X-Http-Status-Code-Override", "308")
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+		w.WriteHeader(http.StatusPermanentRedirect)
+		return
+	}
+
+	s.mu.Unlock()
+
+	rec := httptest.NewRecorder()
+	s.finishUpload(rec, r, session.fileID, session.metadata, session.data)
+
+	// 成功時記錄文件 ID，失敗時會話失效
+	var result struct {
+		ID string `json:"id"`
+	}
+	s.mu.Lock()
+	if rec.Code == http.StatusOK && json.Unmarshal(rec.Body.Bytes(), &result) == nil {
+		session.done = result.ID
+	} else {
+		delete(s.sessions, uploadID)
+	}
+	s.mu.Unlock()
+
+	for k, v := range rec.Header() {
+		w.Header()[k] = v
+	}
+	w.WriteHeader(rec.Code)
+	_, _ = w.Write(rec.Body.Bytes())
+}
+
+// parseContentRange 解析 Content-Range 頭
+// 返回起止偏移（狀態查詢時為 -1）和總大小（未知時為 -1）
+func parseContentRange(header string, length int) (start, end, total int64, err error) {
+	start, end, total = -1, -1, -1
+	if header == "" {
+		// 單次上傳全部內容
+		if length == 0 {
+			return -1, -1, 0, nil
+		}
+		return 0, int64(length) - 1, int64(length), nil
+	}
+
+	spec, ok := strings.CutPrefix(header, "bytes ")
+	if !ok {
+		return 0, 0, 0, fmt.Errorf("invalid Content-Range: %s", header)
+	}
+	rangePart, totalPart, ok := strings.Cut(spec, "/")
+	if !ok {
+		return 0, 0, 0, fmt.Errorf("invalid Content-Range: %s", header)
+	}
+	if totalPart != "*" {
+		if total, err = strconv.ParseInt(totalPart, 10, 64); err != nil {
+			return 0, 0, 0, fmt.Errorf("invalid Content-Range: %s", header)
+		}
+	}
+	if rangePart == "*" {
+		return -1, -1, total, nil
+	}
+
+	from, to, ok := strings.Cut(rangePart, "-")
+	if !ok {
+		return 0, 0, 0, fmt.Errorf("invalid Content-Range: %s", header)
+	}
+	if start, err = strconv.ParseInt(from, 10, 64); err != nil {
+		return 0, 0, 0, fmt.Errorf("invalid Content-Range: %s", header)
+	}
+	if end, err = strconv.ParseInt(to, 10, 64); err != nil {
+		return 0, 0, 0, fmt.Errorf("invalid Content-Range: %s", header)
+	}
+	if end-start+1 != int64(length) {
+		return 0, 0, 0, fmt.Errorf("Content-Range does not match body length")
+	}
+	return start, end, total, nil
+}
+
+// readMultipart 解析 multipart/related 請求，返回元數據和文件內容
+func readMultipart(r *http.Request) ([]byte, []byte, error) {
+	mediaType, params, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil || !strings.HasPrefix(mediaType, "multipart/") {
+		return nil, nil, fmt.Errorf("multipart upload requires a multipart Content-Type")
+	}
+
+	reader := multipart.NewReader(r.Body, params["boundary"])
+	var parts [][]byte
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		data, err := io.ReadAll(part)
+		if err != nil {
+			return nil, nil, err
+		}
+		parts = append(parts, data)
+	}
+
+	if len(parts) != 2 {
+		return nil, nil, fmt.Errorf("multipart upload requires exactly 2 parts, got %d", len(parts))
+	}
+	return parts[0], parts[1], nil
+}
+
+// applyMetadata 將 JSON 元數據應用到文件
+func applyMetadata(file *File, metadata []byte) error {
+	metadata = bytes.TrimSpace(metadata)
+	if len(metadata) == 0 || string(metadata) == "null" {
+		return nil
+	}
+
+	var m struct {
+		Name          *string            `json:"name"`
+		MimeType      *string            `json:"mimeType"`
+		Description   *string            `json:"description"`
+		Parents       []string           `json:"parents"`
+		AppProperties map[string]*string `json:"appProperties"`
+		Properties    map[string]*string `json:"properties"`
+		Trashed       *bool              `json:"trashed"`
+		DriveID       *string            `json:"driveId"`
+		ModifiedTime  *time.Time         `json:"modifiedTime"`
+	}
+	if err := json.Unmarshal(metadata, &m); err != nil {
+		return err
+	}
+
+	if m.Name != nil {
+		file.Name = *m.Name
+	}
+	if m.MimeType != nil {
+		file.MimeType = *m.MimeType
+	}
+	if m.Description != nil {
+		file.Description = *m.Description
+	}
+	if m.Parents != nil {
+		file.Parents = m.Parents
+	}
+	if m.Trashed != nil {
+		file.Trashed = *m.Trashed
+	}
+	if m.ModifiedTime != nil {
+		file.ModifiedTime = *m.ModifiedTime
+	}
+	file.AppProperties = mergeProperties(file.AppProperties, m.AppProperties)
+	file.Properties = mergeProperties(file.Properties, m.Properties)
+	return nil
+}
+
+// mergeProperties 合併屬性，值為 null 時刪除對應鍵
+func mergeProperties(current map[string]string, updates map[string]*string) map[string]string {
+	if len(updates) == 0 {
+		return current
+	}
+	if current == nil {
+		current = make(map[string]string)
+	}
+	for k, v := range updates {
+		if v == nil {
+			delete(current, k)
+		} else {
+			current[k] = *v
+		}
+	}
+	return current
+}
+
+// resolveParentsLocked 校驗父級，子文件繼承父級所屬的共享雲端硬碟
+func (s *Server) resolveParentsLocked(file *File) error {
+	if len(file.Parents) == 0 {
+		file.Parents = []string{RootID}
+		return nil
+	}
+
+	for _, parent := range file.Parents {
+		switch {
+		case parent == RootID || parent == AppDataFolderID:
+		case s.isDriveLocked(parent):
+			file.DriveID = parent
+		default:
+			p, ok := s.files[parent]
+			if !ok {
+				return fmt.Errorf("File not found: %s.", parent)
+			}
+			file.DriveID = p.DriveID
+		}
+	}
+	return nil
+}
+
+// inSpaceLocked 判斷文件是否屬於指定空間（drive 或 appDataFolder）
+func (s *Server) inSpaceLocked(f *File, spaces string) bool {
+	inAppData := s.inAppDataLocked(f, 0)
+	for _, space := range splitList(spaces) {
+		if (space == "drive" && !inAppData) || (space == AppDataFolderID && inAppData) {
+			return true
+		}
+	}
+	return false
+}
+
+// inAppDataLocked 判斷文件是否位於應用數據文件夾中
+func (s *Server) inAppDataLocked(f *File, depth int) bool {
+	if depth > 64 {
+		return false
+	}
+	for _, parent := range f.Parents {
+		if parent == AppDataFolderID {
+			return true
+		}
+		if p, ok := s.files[parent]; ok && s.inAppDataLocked(p, depth+1) {
+			return true
+		}
+	}
+	return false
+}
+
+// isDriveLocked 判斷 ID 是否為共享雲端硬碟
+func (s *Server) isDriveLocked(id string) bool {
+	for _, d := range s.drives {
+		if d.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+// allDrivesVisible 判斷請求是否可訪問文件（共享雲端硬碟中的文件要求 supportsAllDrives=true）
+func allDrivesVisible(f *File, r *http.Request) bool {
+	return f.DriveID == "" || r.URL.Query().Get("supportsAllDrives") == "true"
+}
+
+// exceedsQuotaLocked 判斷新增 delta 字節後是否超出存儲空間上限（共享雲端硬碟中的文件不計入用戶配額）
+func (s *Server) exceedsQuotaLocked(delta int64) bool {
+	if s.quotaLimit <= 0 || delta <= 0 {
+		return false
+	}
+	var usage int64
+	for _, f := range s.files {
+		if f.DriveID == "" {
+			usage += int64(len(f.Content))
+		}
+	}
+	return usage+delta > s.quotaLimit
+}
+
+// newIDLocked 生成新的文件 ID
+func (s *Server) newIDLocked() string {
+	s.nextID++
+	return fmt.Sprintf("file-%d", s.nextID)
+}
+
+// putLocked 保存文件
+func (s *Server) putLocked(f *File) {
+	if _, exists := s.files[f.ID]; !exists {
+		s.order = append(s.order, f.ID)
+	}
+	s.files[f.ID] = f
+}
+
+// deleteLocked 刪除文件及其所有子文件
+func (s *Server) deleteLocked(id string) {
+	delete(s.files, id)
+	s.order = removeString(s.order, id)
+
+	for _, childID := range append([]string(nil), s.order...) {
+		if child, ok := s.files[childID]; ok && containsString(child.Parents, id) {
+			s.deleteLocked(childID)
+		}
+	}
+}
+
+// fileJSON 將文件轉換為 Drive API 響應格式
+func fileJSON(f *File) map[string]interface{} {
+	m := map[string]interface{}{
+		"kind":         "drive#file",
+		"id":           f.ID,
+		"name":         f.Name,
+		"mimeType":     f.MimeType,
+		"parents":      f.Parents,
+		"trashed":      f.Trashed,
+		"createdTime":  f.CreatedTime.UTC().Format(time.RFC3339Nano),
+		"modifiedTime": f.ModifiedTime.UTC().Format(time.RFC3339Nano),
+	}
+	if f.Description != "" {
+		m["description"] = f.Description
+	}
+	if len(f.AppProperties) > 0 {
+		m["appProperties"] = f.AppProperties
+	}
+	if len(f.Properties) > 0 {
+		m["properties"] = f.Properties
+	}
+	if f.DriveID != "" {
+		m["driveId"] = f.DriveID
+	}
+	if !f.IsFolder() {
+		m["size"] = strconv.Itoa(len(f.Content))
+		m["md5Checksum"] = f.MD5()
+	}
+	return m
+}
+
+// writeJSON 輸出 JSON 響應
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// writeError 輸出 Google API 格式的錯誤響應
+func writeError(w http.ResponseWriter, status int, reason, message string) {
+	writeJSON(w, status, map[string]interface{}{
+		"error": map[string]interface{}{
+			"code":    status,
+			"message": message,
+			"errors": []map[string]interface{}{{
+				"domain":  errorDomain(reason),
+				"reason":  reason,
+				"message": message,
+			}},
+		},
+	})
+}
+
+// errorDomain 返回錯誤原因對應的 domain
+func errorDomain(reason string) string {
+	switch reason {
+	case "userRateLimitExceeded", "rateLimitExceeded", "dailyLimitExceeded", "storageQuotaExceeded":
+		return "usageLimits"
+	default:
+		return "global"
+	}
+}
+
+func mimeTypeOrDefault(mimeType string) string {
+	if mimeType == "" {
+		return "application/octet-stream"
+	}
+	return mimeType
+}
+
+func hasField(metadata []byte, field string) bool {
+	var m map[string]json.RawMessage
+	if err := json.Unmarshal(metadata, &m); err != nil {
+		return false
+	}
+	_, ok := m[field]
+	return ok
+}
+
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+func removeString(values []string, value string) []string {
+	result := values[:0:0]
+	for _, v := range values {
+		if v != value {
+			result = append(result, v)
+		}
+	}
+	return result
+}
+
+func cloneMap(m map[string]string) map[string]string {
+	if m == nil {
+		return nil
+	}
+	c := make(map[string]string, len(m))
+	for k, v := range m {
+		c[k] = v
+	}
+	return c
+}
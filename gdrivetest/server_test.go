@@ -0,0 +1,335 @@
+package gdrivetest
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// do 以模擬服務接受的 Token 發送請求
+func do(t *testing.T, s *Server, method, path string, header map[string]string, body string) *http.Response {
+	t.Helper()
+
+	url := path
+	if strings.HasPrefix(path, "/") {
+		url = s.URL() + path
+	}
+	req, err := http.NewRequest(method, url, strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Authorization", "Bearer "+AccessToken)
+	for k, v := range header {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := s.HTTPClient().Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { resp.Body.Close() })
+	return resp
+}
+
+// apiError 解析 Google API 格式的錯誤響應
+func apiError(t *testing.T, resp *http.Response) (code int, reason string) {
+	t.Helper()
+
+	var body struct {
+		Error struct {
+			Code   int `json:"code"`
+			Errors []struct {
+				Reason string `json:"reason"`
+			} `json:"errors"`
+		} `json:"error"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		t.Fatalf("decode error response: %v", err)
+	}
+	if len(body.Error.Errors) == 0 {
+		t.Fatalf("error response has no errors: %+v", body)
+	}
+	return body.Error.Code, body.Error.Errors[0].Reason
+}
+
+// putChunk 發送可續傳上傳分塊，返回狀態碼和 Range 響應頭
+func putChunk(t *testing.T, s *Server, location, contentRange, data string, header map[string]string) (int, string) {
+	t.Helper()
+
+	h := map[string]string{"Content-Range": contentRange}
+	for k, v := range header {
+		h[k] = v
+	}
+	resp := do(t, s, http.MethodPut, location, h, data)
+	return resp.StatusCode, resp.Header.Get("Range")
+}
+
+func TestResumableUpload(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+
+	resp := do(t, s, http.MethodPost, "/upload/drive/v3/files?uploadType=resumable", map[string]string{
+		"Content-Type":            "application/json",
+		"X-Upload-Content-Length": "10",
+	}, `{"name": "big.bin"}`)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("start session: status %d", resp.StatusCode)
+	}
+	location := resp.Header.Get("Location")
+	if location == "" {
+		t.Fatal("start session: missing Location")
+	}
+
+	steps := []struct {
+		name         string
+		contentRange string
+		data         string
+		header       map[string]string
+		status       int
+		rangeHeader  string
+	}{
+		{"未收到數據時查詢狀態", "bytes */10", "", nil, http.StatusPermanentRedirect, ""},
+		{"第一個分塊", "bytes 0-3/10", "0123", nil, http.StatusPermanentRedirect, "bytes=0-3"},
+		{"查詢狀態", "bytes */10", "", nil, http.StatusPermanentRedirect, "bytes=0-3"},
+		{"重疊部分被丟棄", "bytes 2-5/10", "2345", nil, http.StatusPermanentRedirect, "bytes=0-5"},
+		{"起點超過已接收偏移", "bytes 8-9/10", "89", nil, http.StatusBadRequest, ""},
+		{"Content-Range 與請求體長度不符", "bytes 6-7/10", "6", nil, http.StatusBadRequest, ""},
+		{"X-GUploader-No-308", "bytes 6-7/10", "67", map[string]string{"X-GUploader-No-308": "yes"}, http.StatusOK, "bytes=0-7"},
+	}
+	for _, step := range steps {
+		status, got := putChunk(t, s, location, step.contentRange, step.data, step.header)
+		if status != step.status || got != step.rangeHeader {
+			t.Fatalf("%s: status %d Range %q, want %d %q", step.name, status, got, step.status, step.rangeHeader)
+		}
+	}
+
+	resp = do(t, s, http.MethodPut, location, map[string]string{"Content-Range": "bytes 8-9/10"}, "89")
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("最後一個分塊: status %d", resp.StatusCode)
+	}
+	var uploaded struct {
+		ID          string `json:"id"`
+		Name        string `json:"name"`
+		Size        string `json:"size"`
+		Md5Checksum string `json:"md5Checksum"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&uploaded); err != nil {
+		t.Fatal(err)
+	}
+
+	f, ok := s.File(uploaded.ID)
+	if !ok {
+		t.Fatalf("file %s not found", uploaded.ID)
+	}
+	if string(f.Content) != "0123456789" {
+		t.Fatalf("content = %q", f.Content)
+	}
+	if uploaded.Name != "big.bin" || uploaded.Size != "10" || uploaded.Md5Checksum != f.MD5() {
+		t.Fatalf("response = %+v", uploaded)
+	}
+
+	// 完成後再次查詢返回上傳結果，不會重複創建文件
+	resp = do(t, s, http.MethodPut, location, map[string]string{"Content-Range": "bytes */10"}, "")
+	var again struct {
+		ID string `json:"id"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&again); err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != http.StatusOK || again.ID != uploaded.ID {
+		t.Fatalf("completed session: status %d id %s, want 200 %s", resp.StatusCode, again.ID, uploaded.ID)
+	}
+	if n := len(s.Files()); n != 1 {
+		t.Fatalf("files = %d, want 1", n)
+	}
+}
+
+func TestResumableUploadUpdate(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+
+	id := s.AddFile(File{Name: "a.txt", Content: []byte("old"), AppProperties: map[string]string{"k": "v"}})
+
+	resp := do(t, s, http.MethodPatch, "/upload/drive/v3/files/"+id+"?uploadType=resumable", map[string]string{
+		"Content-Type": "application/json",
+	}, `{"appProperties": {"sha256": "abc"}}`)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("start session: status %d", resp.StatusCode)
+	}
+
+	// 總大小未知時以最後一個分塊的 Content-Range 確定
+	location := resp.Header.Get("Location")
+	if status, rng := putChunk(t, s, location, "bytes 0-2/*", "new", nil); status != http.StatusPermanentRedirect || rng != "bytes=0-2" {
+		t.Fatalf("chunk: status %d Range %q", status, rng)
+	}
+	if status, _ := putChunk(t, s, location, "bytes 3-4/5", "er", nil); status != http.StatusOK {
+		t.Fatalf("final chunk: status %d", status)
+	}
+
+	f, _ := s.File(id)
+	if string(f.Content) != "newer" || f.AppProperties["k"] != "v" || f.AppProperties["sha256"] != "abc" {
+		t.Fatalf("file = %q %v", f.Content, f.AppProperties)
+	}
+
+	resp = do(t, s, http.MethodPatch, "/upload/drive/v3/files/missing?uploadType=resumable", nil, "{}")
+	if resp.StatusCode != http.StatusNotFound {
+		t.Fatalf("missing file: status %d, want 404", resp.StatusCode)
+	}
+}
+
+func TestFaultPresets(t *testing.T) {
+	tests := []struct {
+		name   string
+		fault  Fault
+		status int
+		reason string
+	}{
+		{"403", RateLimitExceeded, http.StatusForbidden, "userRateLimitExceeded"},
+		{"429", TooManyRequests, http.StatusTooManyRequests, "rateLimitExceeded"},
+		{"500", InternalError, http.StatusInternalServerError, "internalError"},
+		{"503", ServiceUnavailable, http.StatusServiceUnavailable, "backendError"},
+		{"502 默認原因", Fault{Status: http.StatusBadGateway}, http.StatusBadGateway, "backendError"},
+		{"403 默認原因", Fault{Status: http.StatusForbidden}, http.StatusForbidden, "userRateLimitExceeded"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewServer()
+			defer s.Close()
+
+			s.FailNext(1, tt.fault)
+			resp := do(t, s, http.MethodGet, "/drive/v3/files", nil, "")
+			if resp.StatusCode != tt.status {
+				t.Fatalf("status %d, want %d", resp.StatusCode, tt.status)
+			}
+			if code, reason := apiError(t, resp); code != tt.status || reason != tt.reason {
+				t.Fatalf("error = %d %s, want %d %s", code, reason, tt.status, tt.reason)
+			}
+
+			if resp := do(t, s, http.MethodGet, "/drive/v3/files", nil, ""); resp.StatusCode != http.StatusOK {
+				t.Fatalf("after fault: status %d", resp.StatusCode)
+			}
+		})
+	}
+}
+
+func TestInjectFault(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+
+	s.InjectFault(Fault{
+		Method:     http.MethodPost,
+		Path:       "/upload/drive/v3/files",
+		Status:     http.StatusTooManyRequests,
+		RetryAfter: 3,
+		Times:      2,
+	})
+
+	// 方法或路徑不匹配的請求不受影響
+	if resp := do(t, s, http.MethodGet, "/drive/v3/files", nil, ""); resp.StatusCode != http.StatusOK {
+		t.Fatalf("GET files: status %d", resp.StatusCode)
+	}
+	if resp := do(t, s, http.MethodPost, "/drive/v3/files", map[string]string{"Content-Type": "application/json"}, `{"name": "a"}`); resp.StatusCode != http.StatusOK {
+		t.Fatalf("POST files: status %d", resp.StatusCode)
+	}
+
+	upload := func() *http.Response {
+		return do(t, s, http.MethodPost, "/upload/drive/v3/files?uploadType=media", nil, "data")
+	}
+	for i := 0; i < 2; i++ {
+		resp := upload()
+		if resp.StatusCode != http.StatusTooManyRequests || resp.Header.Get("Retry-After") != "3" {
+			t.Fatalf("upload %d: status %d Retry-After %q", i, resp.StatusCode, resp.Header.Get("Retry-After"))
+		}
+	}
+	if resp := upload(); resp.StatusCode != http.StatusOK {
+		t.Fatalf("upload after Times exhausted: status %d", resp.StatusCode)
+	}
+	// 失敗的請求不會創建文件
+	if n := len(s.Files()); n != 2 {
+		t.Fatalf("files = %d, want 2", n)
+	}
+
+	// Times 為 0 時一直生效，直到 ClearFaults
+	s.InjectFault(Fault{Status: http.StatusInternalServerError, Message: "boom"})
+	for i := 0; i < 3; i++ {
+		if resp := do(t, s, http.MethodGet, "/drive/v3/about?fields=user", nil, ""); resp.StatusCode != http.StatusInternalServerError {
+			t.Fatalf("request %d: status %d", i, resp.StatusCode)
+		}
+	}
+	s.ClearFaults()
+	if resp := do(t, s, http.MethodGet, "/drive/v3/about?fields=user", nil, ""); resp.StatusCode != http.StatusOK {
+		t.Fatalf("after ClearFaults: status %d", resp.StatusCode)
+	}
+}
+
+func TestRequestsAndAuth(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+
+	req, _ := http.NewRequest(http.MethodGet, s.URL()+"/drive/v3/files", nil)
+	req.Header.Set("Authorization", "Bearer wrong")
+	resp, err := s.HTTPClient().Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("wrong token: status %d, want 401", resp.StatusCode)
+	}
+
+	do(t, s, http.MethodGet, "/drive/v3/files?q="+strings.ReplaceAll("name = 'a'", " ", "+"), nil, "")
+	requests := s.Requests()
+	if len(requests) != 2 {
+		t.Fatalf("requests = %d, want 2", len(requests))
+	}
+	if r := requests[1]; r.Method != http.MethodGet || r.Path != "/drive/v3/files" || !strings.Contains(r.Query, "q=") {
+		t.Fatalf("request = %+v", r)
+	}
+
+	s.ResetRequests()
+	if n := len(s.Requests()); n != 0 {
+		t.Fatalf("requests after reset = %d", n)
+	}
+}
+
+func TestListPagination(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+
+	for i := 0; i < 5; i++ {
+		s.AddFile(File{Name: "f" + strconv.Itoa(i)})
+	}
+
+	var names []string
+	token := ""
+	for pages := 0; ; pages++ {
+		if pages > 5 {
+			t.Fatal("too many pages")
+		}
+		resp := do(t, s, http.MethodGet, "/drive/v3/files?pageSize=2&pageToken="+token, nil, "")
+		var list struct {
+			NextPageToken string `json:"nextPageToken"`
+			Files         []struct {
+				Name string `json:"name"`
+			} `json:"files"`
+		}
+		data, _ := io.ReadAll(resp.Body)
+		if err := json.Unmarshal(data, &list); err != nil {
+			t.Fatalf("decode %s: %v", data, err)
+		}
+		for _, f := range list.Files {
+			names = append(names, f.Name)
+		}
+		if list.NextPageToken == "" {
+			break
+		}
+		token = list.NextPageToken
+	}
+	if got := strings.Join(names, ","); got != "f0,f1,f2,f3,f4" {
+		t.Fatalf("names = %s", got)
+	}
+}
@@ -0,0 +1,12 @@
+package gdrive
+
+import "testing"
+
+// testLogger 將日志輸出到 t.Logf，只在測試失敗或 -v 時顯示
+type testLogger struct {
+	t testing.TB
+}
+
+func (l testLogger) Infof(format string, v ...interface{})    { l.t.Logf(format, v...) }
+func (l testLogger) Warningf(format string, v ...interface{}) { l.t.Logf(format, v...) }
+func (l testLogger) Errorf(format string, v ...interface{})   { l.t.Logf(format, v...) }
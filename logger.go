@@ -33,3 +33,11 @@ func (l *defaultLogger) Errorf(format string, v ...interface{}) {
 func newDefaultLogger() Logger {
 	return &defaultLogger{}
 }
+
+// logger 返回配置中的日志實例（未設置時使用默認實現）
+func (c *Config) logger() Logger {
+	if c.Logger != nil {
+		return c.Logger
+	}
+	return newDefaultLogger()
+}
@@ -0,0 +1,154 @@
+package gdrive
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"html"
+	"net"
+	"net/http"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+// loopbackTimeout 等待瀏覽器回調的最長時間
+const loopbackTimeout = 5 * time.Minute
+
+// AuthURLPrompter 回環授權交互接口（可選）
+// DevicePrompter 實現該接口時，回環授權流程通過它展示授權網址和授權結果；否則輸出到 Logger
+type AuthURLPrompter interface {
+	// PromptAuthURL 展示授權網址
+	PromptAuthURL(authURL string, browserOpened bool)
+
+	// LoopbackAuthorized 用戶已在瀏覽器中完成授權
+	LoopbackAuthorized()
+}
+
+// loopbackResult 回調接收到的授權結果
+type loopbackResult struct {
+	code string
+	err  error
+}
+
+// getTokenFromLoopbackFlow 通過本地回環重定向（PKCE）獲取新 Token
+// 在 127.0.0.1 隨機端口啟動臨時 HTTP 服務接收授權碼，支持 drive 等 Device Flow 不允許的權限範圍
+func getTokenFromLoopbackFlow(ctx context.Context, config *Config, oauthConfig *oauth2.Config) (*oauth2.Token, error) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, config.newError(CodeLoopbackListenFailed, err)
+	}
+	defer listener.Close()
+
+	loopbackConfig := *oauthConfig
+	loopbackConfig.RedirectURL = fmt.Sprintf("http://%s/", listener.Addr().String())
+
+	state, err := randomState(config.language())
+	if err != nil {
+		return nil, err
+	}
+	verifier := oauth2.GenerateVerifier()
+
+	results := make(chan loopbackResult, 1)
+	server := &http.Server{
+		Handler:           loopbackHandler(config, state, results),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	go func() { _ = server.Serve(listener) }()
+	defer server.Close()
+
+	// 打開瀏覽器並展示授權網址
+	authURL := loopbackConfig.AuthCodeURL(state,
+		oauth2.AccessTypeOffline,
+		oauth2.ApprovalForce,
+		oauth2.S256ChallengeOption(verifier),
+	)
+	browserOpened := config.launchBrowser(authURL)
+	urlPrompter, ok := config.prompter().(AuthURLPrompter)
+	if ok {
+		urlPrompter.PromptAuthURL(authURL, browserOpened)
+	} else {
+		config.logger().Infof(config.text(msgLoopbackURL), authURL)
+	}
+
+	// 等待瀏覽器回調
+	ctx, cancel := context.WithTimeout(ctx, loopbackTimeout)
+	defer cancel()
+
+	var result loopbackResult
+	select {
+	case result = <-results:
+	case <-ctx.Done():
+		return nil, config.newError(CodeAuthTimeout, ctx.Err())
+	}
+	if result.err != nil {
+		return nil, result.err
+	}
+
+	// 使用授權碼和 PKCE 驗證碼換取 Token
+	token, err := loopbackConfig.Exchange(ctx, result.code, oauth2.VerifierOption(verifier))
+	if err != nil {
+		return nil, config.newError(CodeTokenExchangeFailed, err)
+	}
+
+	if urlPrompter != nil {
+		urlPrompter.LoopbackAuthorized()
+	} else {
+		config.logger().Infof(config.text(msgLoopbackDone))
+	}
+	return token, nil
+}
+
+// loopbackHandler 處理授權重定向回調
+func loopbackHandler(config *Config, state string, results chan<- loopbackResult) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// 忽略 favicon 等無關請求
+		if r.URL.Path != "/" {
+			http.NotFound(w, r)
+			return
+		}
+
+		query := r.URL.Query()
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+
+		// state 不匹配的請求不是本次授權的回調，直接拒絕且不影響等待中的流程
+		if query.Get("state") != state {
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprintf(w, "<h3>%s</h3><p>%s</p>", config.text(msgLoopbackFailure), config.text(msgLoopbackState))
+			return
+		}
+
+		var result loopbackResult
+		switch {
+		case query.Get("error") != "":
+			result.err = config.newError(CodeAuthDenied, nil, query.Get("error"))
+		case query.Get("code") == "":
+			result.err = config.newError(CodeMissingAuthCode, nil)
+		default:
+			result.code = query.Get("code")
+		}
+
+		if result.err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprintf(w, "<h3>%s</h3><p>%s</p>", config.text(msgLoopbackFailure), html.EscapeString(result.err.Error()))
+		} else {
+			fmt.Fprintf(w, "<h3>%s</h3><p>%s</p>", config.text(msgLoopbackSuccess), config.text(msgLoopbackClose))
+		}
+
+		// 只接收第一個結果
+		select {
+		case results <- result:
+		default:
+		}
+	})
+}
+
+// randomState 生成隨機 state 參數，防止跨站請求偽造
+func randomState(language Language) (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", language.newError(CodeRandomFailed, err)
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
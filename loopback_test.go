@@ -0,0 +1,366 @@
+package gdrive_test
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"golang.org/x/oauth2"
+
+	"github.com/Digman/gdrive"
+	"github.com/Digman/gdrive/gdrivetest"
+)
+
+// fakeOAuth 模擬 Google OAuth 授權端點和 Token 端點
+type fakeOAuth struct {
+	*httptest.Server
+
+	mu         sync.Mutex
+	challenge  string          // 授權請求中的 PKCE code_challenge
+	exchanged  int             // 成功換取 Token 的次數
+	refreshed  int             // 成功刷新 Token 的次數
+	valid      map[string]bool // 可用於刷新的 Refresh Token
+	newRefresh string          // 下次換取 Token 時返回的 Refresh Token
+	expiresIn  int             // 返回的 Access Token 有效期（秒）
+}
+
+func newFakeOAuth(t *testing.T) *fakeOAuth {
+	o := &fakeOAuth{valid: make(map[string]bool), newRefresh: "test-refresh", expiresIn: 3600}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/token", o.handleToken)
+	o.Server = httptest.NewServer(mux)
+	t.Cleanup(o.Close)
+	return o
+}
+
+// handleToken 校驗授權碼和 PKCE 驗證碼並返回 Token
+func (o *fakeOAuth) handleToken(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if r.PostForm.Get("grant_type") == "refresh_token" {
+		o.handleRefresh(w, r.PostForm.Get("refresh_token"))
+		return
+	}
+
+	sum := sha256.Sum256([]byte(r.PostForm.Get("code_verifier")))
+	if r.PostForm.Get("grant_type") != "authorization_code" ||
+		r.PostForm.Get("code") != "test-code" ||
+		base64.RawURLEncoding.EncodeToString(sum[:]) != o.challenge {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprint(w, `{"error":"invalid_grant"}`)
+		return
+	}
+
+	o.exchanged++
+	o.valid[o.newRefresh] = true
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"access_token":  gdrivetest.AccessToken,
+		"refresh_token": o.newRefresh,
+		"token_type":    "Bearer",
+		"expires_in":    o.expiresIn,
+		"scope":         "https://www.googleapis.com/auth/drive",
+	})
+}
+
+// handleRefresh 使用 Refresh Token 刷新 Access Token（響應不返回新的 Refresh Token 和 scope）
+func (o *fakeOAuth) handleRefresh(w http.ResponseWriter, refreshToken string) {
+	w.Header().Set("Content-Type", "application/json")
+	if !o.valid[refreshToken] {
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprint(w, `{"error":"invalid_grant","error_description":"Token has been expired or revoked."}`)
+		return
+	}
+
+	o.refreshed++
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"access_token": gdrivetest.AccessToken,
+		"token_type":   "Bearer",
+		"expires_in":   o.expiresIn,
+	})
+}
+
+// setRefreshToken 設置 Refresh Token 是否可用
+func (o *fakeOAuth) setRefreshToken(token string, valid bool) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.valid[token] = valid
+}
+
+// counts 返回換取和刷新 Token 的次數
+func (o *fakeOAuth) counts() (exchanged, refreshed int) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.exchanged, o.refreshed
+}
+
+// writeCredentials 寫入指向模擬 OAuth 服務的桌面應用憑據文件
+func (o *fakeOAuth) writeCredentials(t *testing.T) string {
+	path := filepath.Join(t.TempDir(), "credentials.json")
+	data, err := json.Marshal(map[string]interface{}{
+		"installed": map[string]interface{}{
+			"client_id":     "test-client",
+			"client_secret": "test-secret",
+			"auth_uri":      o.URL + "/auth",
+			"token_uri":     o.URL + "/token",
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// browserPrompter 模擬用戶在瀏覽器中完成授權：收到授權網址後請求回環地址
+type browserPrompter struct {
+	t          *testing.T
+	oauth      *fakeOAuth
+	query      func(state string) url.Values // 回調參數
+	status     chan int                      // 回調響應狀態碼
+	authorized bool                          // 是否調用了 LoopbackAuthorized
+	device     bool                          // 是否調用了 DeviceAuthorized
+}
+
+func (p *browserPrompter) PromptAuthURL(authURL string, browserOpened bool) {
+	if browserOpened {
+		p.t.Error("DisableBrowser 時不應打開瀏覽器")
+	}
+	u, err := url.Parse(authURL)
+	if err != nil {
+		p.t.Error(err)
+		return
+	}
+	params := u.Query()
+	if params.Get("code_challenge_method") != "S256" || params.Get("access_type") != "offline" {
+		p.t.Errorf("授權網址缺少 PKCE 或 offline 參數: %s", authURL)
+	}
+
+	p.oauth.mu.Lock()
+	p.oauth.challenge = params.Get("code_challenge")
+	p.oauth.mu.Unlock()
+
+	redirect := params.Get("redirect_uri") + "?" + p.query(params.Get("state")).Encode()
+	go func() {
+		resp, err := http.Get(redirect)
+		if err != nil {
+			p.t.Error(err)
+			p.status <- 0
+			return
+		}
+		resp.Body.Close()
+		p.status <- resp.StatusCode
+	}()
+}
+
+func (p *browserPrompter) LoopbackAuthorized() { p.authorized = true }
+func (p *browserPrompter) PromptDeviceCode(gdrive.DeviceCode) {
+	p.t.Error("回環授權不應展示 Device Code")
+}
+func (p *browserPrompter) DeviceAuthorized() { p.device = true }
+func (p *browserPrompter) PromptCredentialsSetup(gdrive.CredentialsGuide) {
+	p.t.Error("憑據文件有效")
+}
+
+func TestLoopbackFlowEndToEnd(t *testing.T) {
+	oauth := newFakeOAuth(t)
+	drive := gdrivetest.NewServer()
+	defer drive.Close()
+
+	prompter := &browserPrompter{
+		t:      t,
+		oauth:  oauth,
+		query:  func(state string) url.Values { return url.Values{"state": {state}, "code": {"test-code"}} },
+		status: make(chan int, 1),
+	}
+	store := gdrive.NewMemoryTokenStore(nil)
+	config := &gdrive.Config{
+		Enabled:         true,
+		CredentialsFile: oauth.writeCredentials(t),
+		TokenStore:      store,
+		FolderName:      "backups",
+		Scopes:          []string{"drive"},
+		AuthFlow:        gdrive.AuthFlowLoopback,
+		DisableBrowser:  true,
+		DevicePrompter:  prompter,
+		Logger:          testLogger{t},
+	}
+
+	client, err := gdrive.NewClient(config,
+		gdrive.WithEndpoint(drive.URL()),
+		gdrive.WithHTTPClient(drive.HTTPClient()),
+	)
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+
+	if status := <-prompter.status; status != http.StatusOK {
+		t.Fatalf("回調響應狀態碼 = %d, want 200", status)
+	}
+	if !prompter.authorized || prompter.device {
+		t.Fatalf("LoopbackAuthorized = %v, DeviceAuthorized = %v, want true, false", prompter.authorized, prompter.device)
+	}
+	if oauth.exchanged != 1 {
+		t.Fatalf("換取 Token 次數 = %d, want 1", oauth.exchanged)
+	}
+
+	// Token 已保存，並可用於訪問 Drive
+	token, err := store.Load()
+	if err != nil {
+		t.Fatalf("Token 未保存: %v", err)
+	}
+	if token.RefreshToken != "test-refresh" {
+		t.Fatalf("RefreshToken = %q", token.RefreshToken)
+	}
+	if got := drive.FindByName("backups"); len(got) != 1 {
+		t.Fatalf("備份文件夾數量 = %d, want 1", len(got))
+	}
+	if state := client.AuthState(); state != gdrive.AuthStateAuthenticated {
+		t.Fatalf("AuthState = %v", state)
+	}
+}
+
+func TestLoopbackFlowDenied(t *testing.T) {
+	oauth := newFakeOAuth(t)
+	drive := gdrivetest.NewServer()
+	defer drive.Close()
+
+	prompter := &browserPrompter{
+		t:      t,
+		oauth:  oauth,
+		query:  func(state string) url.Values { return url.Values{"state": {state}, "error": {"access_denied"}} },
+		status: make(chan int, 1),
+	}
+	config := &gdrive.Config{
+		Enabled:         true,
+		CredentialsFile: oauth.writeCredentials(t),
+		TokenStore:      gdrive.NewMemoryTokenStore(nil),
+		FolderName:      "backups",
+		AuthFlow:        gdrive.AuthFlowLoopback,
+		DisableBrowser:  true,
+		DevicePrompter:  prompter,
+		Logger:          testLogger{t},
+	}
+
+	_, err := gdrive.NewClient(config, gdrive.WithEndpoint(drive.URL()), gdrive.WithHTTPClient(drive.HTTPClient()))
+	if !hasErrorCode(err, gdrive.CodeAuthDenied) {
+		t.Fatalf("NewClient = %v, want %s", err, gdrive.CodeAuthDenied)
+	}
+	if status := <-prompter.status; status != http.StatusBadRequest {
+		t.Fatalf("回調響應狀態碼 = %d, want 400", status)
+	}
+	if oauth.exchanged != 0 || prompter.authorized {
+		t.Fatal("拒絕授權後不應換取 Token")
+	}
+}
+
+func TestLoopbackFlowIgnoresForeignState(t *testing.T) {
+	oauth := newFakeOAuth(t)
+	drive := gdrivetest.NewServer()
+	defer drive.Close()
+
+	// 先發送 state 不匹配的回調（應被拒絕且不影響等待），再完成正常授權
+	prompter := &browserPrompter{t: t, oauth: oauth, status: make(chan int, 2)}
+	prompter.query = func(state string) url.Values {
+		return url.Values{"state": {"forged"}, "code": {"test-code"}}
+	}
+	config := &gdrive.Config{
+		Enabled:         true,
+		CredentialsFile: oauth.writeCredentials(t),
+		TokenStore:      gdrive.NewMemoryTokenStore(nil),
+		FolderName:      "backups",
+		AuthFlow:        gdrive.AuthFlowLoopback,
+		DisableBrowser:  true,
+		DevicePrompter:  &forgedThenValid{browserPrompter: prompter},
+		Logger:          testLogger{t},
+	}
+
+	if _, err := gdrive.NewClient(config, gdrive.WithEndpoint(drive.URL()), gdrive.WithHTTPClient(drive.HTTPClient())); err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	if first, second := <-prompter.status, <-prompter.status; first != http.StatusBadRequest || second != http.StatusOK {
+		t.Fatalf("回調響應狀態碼 = %d, %d, want 400, 200", first, second)
+	}
+}
+
+// forgedThenValid 先以偽造的 state 回調，得到響應後再以正確的 state 回調
+type forgedThenValid struct {
+	*browserPrompter
+}
+
+func (p *forgedThenValid) PromptAuthURL(authURL string, browserOpened bool) {
+	p.browserPrompter.PromptAuthURL(authURL, browserOpened)
+	forged := <-p.status
+	p.status <- forged
+
+	p.query = func(state string) url.Values { return url.Values{"state": {state}, "code": {"test-code"}} }
+	p.browserPrompter.PromptAuthURL(authURL, browserOpened)
+}
+
+// failPrompter 在需要用戶授權時使測試失敗
+type failPrompter struct{ t *testing.T }
+
+func (p failPrompter) PromptAuthURL(string, bool)         { p.t.Error("不應重新授權") }
+func (p failPrompter) LoopbackAuthorized()                {}
+func (p failPrompter) PromptDeviceCode(gdrive.DeviceCode) { p.t.Error("不應重新授權") }
+func (p failPrompter) DeviceAuthorized()                  {}
+func (p failPrompter) PromptCredentialsSetup(gdrive.CredentialsGuide) {
+	p.t.Error("憑據文件有效")
+}
+
+func TestWrongPassphraseKeepsToken(t *testing.T) {
+	oauth := newFakeOAuth(t)
+	drive := gdrivetest.NewServer()
+	defer drive.Close()
+
+	tokenFile := filepath.Join(t.TempDir(), "token.json")
+	store := &gdrive.FileTokenStore{Path: tokenFile, Encryption: &gdrive.TokenEncryption{Passphrase: "right"}}
+	if err := store.Save(&oauth2.Token{AccessToken: "access", RefreshToken: "refresh", Expiry: time.Now().Add(time.Hour)}); err != nil {
+		t.Fatal(err)
+	}
+	saved, err := os.ReadFile(tokenFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 口令錯誤時返回解密錯誤，不重新授權覆蓋已保存的 Token
+	_, err = gdrive.NewClient(&gdrive.Config{
+		Enabled:         true,
+		CredentialsFile: oauth.writeCredentials(t),
+		TokenFile:       tokenFile,
+		TokenEncryption: &gdrive.TokenEncryption{Passphrase: "wrong"},
+		FolderName:      "backups",
+		AuthFlow:        gdrive.AuthFlowLoopback,
+		DisableBrowser:  true,
+		DevicePrompter:  failPrompter{t},
+		Logger:          testLogger{t},
+	}, gdrive.WithEndpoint(drive.URL()), gdrive.WithHTTPClient(drive.HTTPClient()))
+	if !hasErrorCode(err, gdrive.CodeDecryptTokenFailed) {
+		t.Fatalf("NewClient = %v, want %s", err, gdrive.CodeDecryptTokenFailed)
+	}
+	if oauth.exchanged != 0 {
+		t.Fatalf("換取 Token 次數 = %d, want 0", oauth.exchanged)
+	}
+	if got, err := os.ReadFile(tokenFile); err != nil || !bytes.Equal(got, saved) {
+		t.Fatalf("Token 文件已被修改: %v", err)
+	}
+}
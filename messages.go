@@ -0,0 +1,557 @@
+package gdrive
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Language 錯誤信息、日志和授權提示使用的語言
+type Language string
+
+const (
+	// LanguageTraditionalChinese 繁體中文（默認）
+	LanguageTraditionalChinese Language = "zh-TW"
+
+	// LanguageEnglish 英文
+	LanguageEnglish Language = "en"
+)
+
+// ErrorCode 錯誤代碼，不隨語言變化，調用方應通過錯誤代碼而不是錯誤信息判斷錯誤類型
+type ErrorCode string
+
+// 錯誤代碼
+const (
+	// 配置錯誤
+	CodeDisabled              ErrorCode = "disabled"
+	CodeMissingCredentials    ErrorCode = "missing_credentials_file"
+	CodeMissingTokenFile      ErrorCode = "missing_token_file"
+	CodeMissingFolderName     ErrorCode = "missing_folder_name"
+	CodeInvalidAuthFlow       ErrorCode = "invalid_auth_flow"
+	CodeInvalidLanguage       ErrorCode = "invalid_language"
+	CodeInvalidBackupInterval ErrorCode = "invalid_backup_interval"
+	CodeMissingBackupPaths    ErrorCode = "missing_backup_paths"
+	CodeInvalidQuotaPolicy    ErrorCode = "invalid_quota_policy"
+	CodeSharedDriveAppData    ErrorCode = "shared_drive_appdata"
+
+	// 授權錯誤
+	CodeReadCredentials        ErrorCode = "read_credentials_failed"
+	CodeParseCredentials       ErrorCode = "parse_credentials_failed"
+	CodeInvalidCredentials     ErrorCode = "invalid_credentials"
+	CodeParseServiceAccount    ErrorCode = "parse_service_account_failed"
+	CodeDeviceAuthFailed       ErrorCode = "device_auth_failed"
+	CodeDeviceCodeFailed       ErrorCode = "device_code_failed"
+	CodeDeviceScopeUnsupported ErrorCode = "device_scope_unsupported"
+	CodeLoopbackAuthFailed     ErrorCode = "loopback_auth_failed"
+	CodeLoopbackListenFailed   ErrorCode = "loopback_listen_failed"
+	CodeAuthDenied             ErrorCode = "auth_denied"
+	CodeMissingAuthCode        ErrorCode = "missing_auth_code"
+	CodeAuthTimeout            ErrorCode = "auth_timeout"
+	CodeTokenExchangeFailed    ErrorCode = "token_exchange_failed"
+	CodeSaveTokenFailed        ErrorCode = "save_token_failed"
+	CodeGetTokenFailed         ErrorCode = "get_token_failed"
+	CodeDeleteTokenFailed      ErrorCode = "delete_token_failed"
+	CodeRevokeTokenFailed      ErrorCode = "revoke_token_failed"
+	CodeUnauthorized           ErrorCode = "unauthorized"
+	CodeLoggedOut              ErrorCode = "logged_out"
+	CodeLogoutUnsupported      ErrorCode = "logout_unsupported"
+	CodeReauthUnsupported      ErrorCode = "reauth_unsupported"
+	CodeReauthCanceled         ErrorCode = "reauth_canceled"
+	CodeInsufficientScope      ErrorCode = "insufficient_scope"
+	CodeMissingTokenSource     ErrorCode = "missing_token_source"
+	CodeRevokeRejected         ErrorCode = "revoke_rejected"
+
+	// Token 存儲錯誤
+	CodeNilToken               ErrorCode = "nil_token"
+	CodeTokenNotFound          ErrorCode = "token_not_found"
+	CodeTokenExpired           ErrorCode = "token_expired"
+	CodeLoadTokenFailed        ErrorCode = "load_token_failed"
+	CodeParseTokenFile         ErrorCode = "parse_token_file_failed"
+	CodeWriteTokenFile         ErrorCode = "write_token_file_failed"
+	CodeTokenEncrypted         ErrorCode = "token_encrypted"
+	CodeEncryptTokenFailed     ErrorCode = "encrypt_token_failed"
+	CodeDecryptTokenFailed     ErrorCode = "decrypt_token_failed"
+	CodeUnsupportedTokenFormat ErrorCode = "unsupported_token_format"
+	CodeInvalidTokenIterations ErrorCode = "invalid_token_iterations"
+	CodeCorruptTokenFile       ErrorCode = "corrupt_token_file"
+	CodeMissingEncryptionKey   ErrorCode = "missing_encryption_key"
+	CodeMissingPassphraseEnv   ErrorCode = "missing_passphrase_env"
+	CodeReadKeyFile            ErrorCode = "read_key_file_failed"
+	CodeEmptyKeyFile           ErrorCode = "empty_key_file"
+	CodeDeriveKeyFailed        ErrorCode = "derive_key_failed"
+	CodeRandomFailed           ErrorCode = "random_failed"
+
+	// 客戶端錯誤
+	CodeAuthFailed           ErrorCode = "auth_failed"
+	CodeCreateServiceFailed  ErrorCode = "create_service_failed"
+	CodeInitFolderFailed     ErrorCode = "init_folder_failed"
+	CodeAboutFailed          ErrorCode = "about_failed"
+	CodeQuotaFailed          ErrorCode = "quota_failed"
+	CodeListDrivesFailed     ErrorCode = "list_drives_failed"
+	CodeSharedDriveNotFound  ErrorCode = "shared_drive_not_found"
+	CodeSharedDriveAmbiguous ErrorCode = "shared_drive_ambiguous"
+	CodeBackupDisabled       ErrorCode = "backup_disabled"
+	CodeBackupRunning        ErrorCode = "backup_running"
+
+	// 文件和文件夾錯誤
+	CodeOpenLocalFile         ErrorCode = "open_local_file_failed"
+	CodeUploadFailed          ErrorCode = "upload_failed"
+	CodeUpdateFailed          ErrorCode = "update_failed"
+	CodeFindFileFailed        ErrorCode = "find_file_failed"
+	CodeQueryFilesFailed      ErrorCode = "query_files_failed"
+	CodeFileNotFound          ErrorCode = "file_not_found"
+	CodeCreateFolderFailed    ErrorCode = "create_folder_failed"
+	CodeQueryFolderFailed     ErrorCode = "query_folder_failed"
+	CodeFolderNotFound        ErrorCode = "folder_not_found"
+	CodeInvalidFolderPath     ErrorCode = "invalid_folder_path"
+	CodeDownloadFailed        ErrorCode = "download_failed"
+	CodeWriteLocalFile        ErrorCode = "write_local_file_failed"
+	CodeChecksumMismatch      ErrorCode = "checksum_mismatch"
+	CodeSaveSHA256Failed      ErrorCode = "save_sha256_failed"
+	CodeMissingUploadLocation ErrorCode = "missing_upload_location"
+)
+
+// Error 帶錯誤代碼的錯誤，錯誤信息按 Config.Language 本地化
+// 可通過 errors.As 獲取錯誤代碼；文件或文件夾不存在時 errors.Is(err, ErrNotFound) 為 true
+// 校驗和不一致時 errors.Is(err, ErrChecksumMismatch) 為 true
+type Error struct {
+	Code    ErrorCode // 錯誤代碼
+	Message string    // 本地化的錯誤信息（不包括 Err）
+	Err     error     // 原始錯誤（可能為 nil）
+}
+
+// Error 實現 error 接口
+func (e *Error) Error() string {
+	if e.Err != nil {
+		return e.Message + ": " + e.Err.Error()
+	}
+	return e.Message
+}
+
+// Unwrap 返回原始錯誤
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Is 按錯誤代碼匹配 ErrNotFound、ErrUnauthorized、ErrChecksumMismatch
+func (e *Error) Is(target error) bool {
+	switch target {
+	case ErrNotFound:
+		return e.Code == CodeFileNotFound || e.Code == CodeFolderNotFound || e.Code == CodeSharedDriveNotFound ||
+			e.Code == CodeTokenNotFound
+	case ErrUnauthorized:
+		return e.Code == CodeUnauthorized || e.Code == CodeLoggedOut
+	case ErrChecksumMismatch:
+		return e.Code == CodeChecksumMismatch
+	default:
+		return false
+	}
+}
+
+// ErrorCodeOf 返回錯誤鏈中最外層 *Error 的錯誤代碼，不存在時返回空字符串
+func ErrorCodeOf(err error) ErrorCode {
+	var e *Error
+	if errors.As(err, &e) {
+		return e.Code
+	}
+	return ""
+}
+
+// messageKey 消息目錄的鍵（錯誤代碼或日志、提示的鍵）
+type messageKey string
+
+// 日志和授權提示
+const (
+	msgRetry             messageKey = "retry"
+	msgAPIError          messageKey = "api_error"
+	msgAPIErrorReason    messageKey = "api_error_reason"
+	msgScopesMissing     messageKey = "scopes_missing"
+	msgScopeInsufficient messageKey = "scope_insufficient"
+	msgLoopbackURL       messageKey = "loopback_url"
+	msgTokenRefreshed    messageKey = "token_refreshed"
+	msgTokenSaveFailed   messageKey = "token_save_failed"
+	msgAuthInvalidated   messageKey = "auth_invalidated"
+	msgReauthFailed      messageKey = "reauth_failed"
+	msgReauthSucceeded   messageKey = "reauth_succeeded"
+	msgBackupStarted     messageKey = "backup_started"
+	msgBackupStopped     messageKey = "backup_stopped"
+	msgBackupSkipped     messageKey = "backup_skipped"
+	msgBackupRunning     messageKey = "backup_running_run"
+	msgBackupScanFailed  messageKey = "backup_scan_failed"
+	msgBackupNothing     messageKey = "backup_nothing"
+	msgBackupCanceled    messageKey = "backup_canceled"
+	msgBackupStatFailed  messageKey = "backup_stat_failed"
+	msgBackupAborted     messageKey = "backup_aborted"
+	msgBackupFileFailed  messageKey = "backup_file_failed"
+	msgBackupCreated     messageKey = "backup_created"
+	msgBackupUpdated     messageKey = "backup_updated"
+	msgBackupUnchanged   messageKey = "backup_unchanged"
+	msgBackupSummary     messageKey = "backup_summary"
+	msgBackupPathFailed  messageKey = "backup_path_failed"
+	msgBackupWalkFailed  messageKey = "backup_walk_failed"
+	msgQuotaCheckFailed  messageKey = "quota_check_failed"
+	msgQuotaWarning      messageKey = "quota_warning"
+	msgQuotaDeferred     messageKey = "quota_deferred"
+	msgQuotaDeferredFile messageKey = "quota_deferred_file"
+	msgUploadResumed     messageKey = "upload_resumed"
+	msgUploadExpired     messageKey = "upload_session_expired"
+	msgJournalFailed     messageKey = "journal_failed"
+	msgTransferProgress  messageKey = "transfer_progress"
+	msgTransferUnknown   messageKey = "transfer_progress_unknown"
+	msgBackupProgress    messageKey = "backup_progress"
+	msgDeviceTitle       messageKey = "device_title"
+	msgDeviceBrowser     messageKey = "device_browser_opened"
+	msgDeviceOpenURL     messageKey = "device_open_url"
+	msgDeviceURL         messageKey = "device_url"
+	msgDeviceUserCode    messageKey = "device_user_code"
+	msgDeviceExpiry      messageKey = "device_expiry"
+	msgWaitingAuth       messageKey = "waiting_auth"
+	msgDeviceAuthorized  messageKey = "device_authorized"
+	msgLoopbackTitle     messageKey = "loopback_title"
+	msgLoopbackBrowser   messageKey = "loopback_browser_opened"
+	msgLoopbackOpenURL   messageKey = "loopback_open_url"
+	msgLoopbackSuccess   messageKey = "loopback_success"
+	msgLoopbackFailure   messageKey = "loopback_failure"
+	msgLoopbackState     messageKey = "loopback_state_mismatch"
+	msgLoopbackClose     messageKey = "loopback_close"
+	msgLoopbackDone      messageKey = "loopback_authorized"
+	msgCredentialsTitle  messageKey = "credentials_title"
+	msgCredentialsPath   messageKey = "credentials_path"
+	msgCredentialsSteps  messageKey = "credentials_steps"
+	msgCredentialsOpened messageKey = "credentials_browser_opened"
+	msgCredentialsManual messageKey = "credentials_manual"
+)
+
+// catalog 消息目錄（值為 fmt 格式字符串）
+var catalog = map[Language]map[messageKey]string{
+	LanguageTraditionalChinese: {
+		messageKey(CodeDisabled):              "Google Drive 模塊未啟用",
+		messageKey(CodeMissingCredentials):    "憑據文件路徑不能為空",
+		messageKey(CodeMissingTokenFile):      "Token 文件路徑不能為空",
+		messageKey(CodeMissingFolderName):     "FolderName 和 FolderID 不能同時為空",
+		messageKey(CodeInvalidAuthFlow):       "不支持的授權流程: %s",
+		messageKey(CodeInvalidLanguage):       "不支持的語言: %s",
+		messageKey(CodeInvalidBackupInterval): "BackupInterval 必須大於 0",
+		messageKey(CodeMissingBackupPaths):    "BackupPaths 不能為空",
+		messageKey(CodeInvalidQuotaPolicy):    "不支持的存儲空間檢查策略: %s",
+		messageKey(CodeSharedDriveAppData):    "共享雲端硬碟不支持僅 drive.appdata 權限範圍，請添加 drive 或 drive.file",
+
+		messageKey(CodeReadCredentials):        "無法讀取憑據文件",
+		messageKey(CodeParseCredentials):       "無法解析憑據文件",
+		messageKey(CodeInvalidCredentials):     "憑據文件格式錯誤：請使用「電視和受限輸入設備」或「已安裝應用」類型的 OAuth2 客戶端，或服務賬號密鑰",
+		messageKey(CodeParseServiceAccount):    "無法解析服務賬號密鑰",
+		messageKey(CodeDeviceAuthFailed):       "設備認證失敗",
+		messageKey(CodeDeviceCodeFailed):       "無法獲取設備代碼",
+		messageKey(CodeDeviceScopeUnsupported): "Device Flow 不支持請求的權限範圍 %s：Google 僅允許 drive.file、drive.appdata 等非敏感範圍，請調整 Scopes，或改用回環授權流程（AuthFlowLoopback）或服務賬號",
+		messageKey(CodeLoopbackAuthFailed):     "瀏覽器授權失敗",
+		messageKey(CodeLoopbackListenFailed):   "無法啟動本地回調服務",
+		messageKey(CodeAuthDenied):             "用戶拒絕授權或授權失敗: %s",
+		messageKey(CodeMissingAuthCode):        "授權回調缺少授權碼",
+		messageKey(CodeAuthTimeout):            "等待授權超時或失敗",
+		messageKey(CodeTokenExchangeFailed):    "換取 Token 失敗",
+		messageKey(CodeSaveTokenFailed):        "保存 Token 失敗",
+		messageKey(CodeGetTokenFailed):         "獲取 Token 失敗",
+		messageKey(CodeDeleteTokenFailed):      "刪除 Token 失敗",
+		messageKey(CodeRevokeTokenFailed):      "撤銷 Token 失敗（本地 Token 已刪除）",
+		messageKey(CodeUnauthorized):           "授權已失效，需要重新授權",
+		messageKey(CodeLoggedOut):              "已登出",
+		messageKey(CodeLogoutUnsupported):      "當前授權模式不支持登出",
+		messageKey(CodeReauthUnsupported):      "當前授權模式不支持重新授權",
+		messageKey(CodeReauthCanceled):         "重新授權已取消",
+		messageKey(CodeInsufficientScope):      "Token 缺少所需的權限範圍",
+		messageKey(CodeMissingTokenSource):     "未配置 TokenSource",
+		messageKey(CodeRevokeRejected):         "撤銷端點返回 %d: %s",
+
+		messageKey(CodeNilToken):               "Token 不能為空",
+		messageKey(CodeTokenNotFound):          "沒有已保存的 Token",
+		messageKey(CodeTokenExpired):           "Token 已過期且無法刷新",
+		messageKey(CodeLoadTokenFailed):        "無法加載 Token",
+		messageKey(CodeParseTokenFile):         "無法解析 Token 文件",
+		messageKey(CodeWriteTokenFile):         "無法寫入 Token 文件",
+		messageKey(CodeTokenEncrypted):         "Token 文件已加密，但未配置解密密鑰",
+		messageKey(CodeEncryptTokenFailed):     "加密 Token 失敗",
+		messageKey(CodeDecryptTokenFailed):     "解密 Token 失敗（密鑰錯誤或文件已損壞）",
+		messageKey(CodeUnsupportedTokenFormat): "不支持的加密 Token 格式: %s/%s",
+		messageKey(CodeInvalidTokenIterations): "加密 Token 文件的迭代次數無效: %d",
+		messageKey(CodeCorruptTokenFile):       "加密 Token 文件已損壞",
+		messageKey(CodeMissingEncryptionKey):   "未配置 Token 加密密鑰",
+		messageKey(CodeMissingPassphraseEnv):   "環境變量 %s 未設置或為空",
+		messageKey(CodeReadKeyFile):            "無法讀取密鑰文件",
+		messageKey(CodeEmptyKeyFile):           "密鑰文件為空: %s",
+		messageKey(CodeDeriveKeyFailed):        "派生密鑰失敗",
+		messageKey(CodeRandomFailed):           "生成隨機數失敗",
+
+		messageKey(CodeAuthFailed):           "認證失敗",
+		messageKey(CodeCreateServiceFailed):  "創建 Drive Service 失敗",
+		messageKey(CodeInitFolderFailed):     "初始化文件夾失敗",
+		messageKey(CodeAboutFailed):          "獲取賬號信息失敗",
+		messageKey(CodeQuotaFailed):          "獲取存儲配額失敗",
+		messageKey(CodeListDrivesFailed):     "列出共享雲端硬碟失敗",
+		messageKey(CodeSharedDriveNotFound):  "共享雲端硬碟不存在: %s",
+		messageKey(CodeSharedDriveAmbiguous): "存在多個名為 %s 的共享雲端硬碟，請改用 SharedDriveID",
+		messageKey(CodeBackupDisabled):       "備份未啟用，請在配置中設置 BackupEnabled = true",
+		messageKey(CodeBackupRunning):        "備份已在運行中",
+
+		messageKey(CodeOpenLocalFile):         "無法打開本地文件",
+		messageKey(CodeUploadFailed):          "上傳文件失敗",
+		messageKey(CodeUpdateFailed):          "更新文件失敗",
+		messageKey(CodeFindFileFailed):        "查找文件失敗",
+		messageKey(CodeQueryFilesFailed):      "查詢文件失敗",
+		messageKey(CodeFileNotFound):          "文件不存在: %s",
+		messageKey(CodeCreateFolderFailed):    "創建文件夾失敗",
+		messageKey(CodeQueryFolderFailed):     "查詢文件夾失敗",
+		messageKey(CodeFolderNotFound):        "文件夾不存在: %s",
+		messageKey(CodeInvalidFolderPath):     "無效的文件夾路徑: %q",
+		messageKey(CodeDownloadFailed):        "下載文件失敗",
+		messageKey(CodeWriteLocalFile):        "無法寫入本地文件",
+		messageKey(CodeChecksumMismatch):      "文件 %s 校驗和不一致（預期 %s，實際 %s）",
+		messageKey(CodeSaveSHA256Failed):      "保存文件 %s 的 SHA-256 失敗",
+		messageKey(CodeMissingUploadLocation): "創建上傳會話的響應缺少會話地址（Location 頭）",
+
+		msgRetry:             "⚠️  %s，%v 後重試（%d/%d）: %v",
+		msgAPIError:          "Drive API 錯誤 %d: %s",
+		msgAPIErrorReason:    "Drive API 錯誤 %d（%s）: %s",
+		msgScopesMissing:     "⚠️  已保存的 Token 缺少權限範圍 %s，需要重新授權",
+		msgScopeInsufficient: "⚠️  Drive 返回權限範圍不足（403），請調用 Reauthorize 重新授權或配置 OnReauth",
+		msgLoopbackURL:       "🔐 請在瀏覽器中打開以下網址完成 Google Drive 授權: %s",
+		msgTokenRefreshed:    "🔑 Google Drive Token 已刷新，有效期至: %s",
+		msgTokenSaveFailed:   "⚠️  保存刷新後的 Token 失敗: %v",
+		msgAuthInvalidated:   "❌ Google Drive 授權已失效（Refresh Token 被撤銷或過期）: %v",
+		msgReauthFailed:      "❌ Google Drive 重新授權失敗: %v",
+		msgReauthSucceeded:   "✅ Google Drive 重新授權成功",
+		msgBackupStarted:     "✅ 定時備份已啟動，間隔: %v",
+		msgBackupStopped:     "✅ 定時備份已停止",
+		msgBackupSkipped:     "❌ 跳過本次備份: %v",
+		msgBackupRunning:     "🔄 開始備份任務...",
+		msgBackupScanFailed:  "❌ 掃描文件失敗: %v",
+		msgBackupNothing:     "ℹ️  沒有文件需要備份",
+		msgBackupCanceled:    "⚠️  備份已取消",
+		msgBackupStatFailed:  "⚠️  訪問文件失敗 %s: %v",
+		msgBackupAborted:     "❌ 備份中止: %v",
+		msgBackupFileFailed:  "❌ 備份失敗 %s: %v",
+		msgBackupCreated:     "✅ 已創建: %s",
+		msgBackupUpdated:     "✅ 已更新: %s",
+		msgBackupUnchanged:   "⏭️ 內容未變化，跳過: %s",
+		msgBackupSummary:     "📊 備份完成 - 成功: %d, 未變化: %d, 失敗: %d",
+		msgBackupPathFailed:  "⚠️  訪問路徑失敗 %s: %v",
+		msgBackupWalkFailed:  "⚠️  掃描目錄失敗 %s: %v",
+		msgQuotaCheckFailed:  "⚠️  跳過存儲空間檢查: %v",
+		msgQuotaWarning:      "⚠️  本次計劃上傳 %s，超出剩餘空間 %s，上傳可能失敗",
+		msgQuotaDeferred:     "⚠️  剩餘空間不足（計劃上傳 %s，剩餘 %s），%d 個文件推遲到下次備份",
+		msgQuotaDeferredFile: "⚠️  已推遲: %s (%s)",
+		msgUploadResumed:     "🔄 從斷點繼續上傳 %s（%s / %s）",
+		msgUploadExpired:     "⚠️  上傳會話已失效，重新上傳: %s",
+		msgJournalFailed:     "⚠️  讀寫續傳日志失敗: %v",
+		msgTransferProgress:  "⏳ %s: %s / %s（%s/s，剩餘 %s）",
+		msgTransferUnknown:   "⏳ %s: %s（%s/s）",
+		msgBackupProgress:    "📊 備份進度: %d / %d 個文件，%s / %s（%s/s，剩餘 %s）",
+		msgDeviceTitle:       "🔐 Google Drive 設備授權",
+		msgDeviceBrowser:     "1. 瀏覽器已自動打開授權頁面",
+		msgDeviceOpenURL:     "1. 請在瀏覽器中打開以下網址",
+		msgDeviceURL:         "2. 網址：%s",
+		msgDeviceUserCode:    "3. 輸入授權碼：%s",
+		msgDeviceExpiry:      "4. 授權碼有效期至：%s",
+		msgWaitingAuth:       "⏳ 等待授權...",
+		msgDeviceAuthorized:  "✅ Google Drive 設備授權成功！",
+		msgLoopbackTitle:     "🔐 Google Drive 瀏覽器授權",
+		msgLoopbackBrowser:   "瀏覽器已自動打開授權頁面，如未打開請手動訪問：",
+		msgLoopbackOpenURL:   "請在瀏覽器中打開以下網址：",
+		msgLoopbackDone:      "✅ Google Drive 瀏覽器授權成功！",
+		msgLoopbackSuccess:   "Google Drive 授權成功",
+		msgLoopbackFailure:   "Google Drive 授權失敗",
+		msgLoopbackState:     "state 參數不匹配",
+		msgLoopbackClose:     "現在可以關閉此頁面並返回應用。",
+		msgCredentialsTitle:  "⚠️  未找到或無法解析憑據文件",
+		msgCredentialsPath:   "預期路徑: %s",
+		msgCredentialsSteps:  "📝 請按照以下步驟獲取憑據文件：\n\n1. 訪問 Google Cloud Console\n2. 創建或選擇項目\n3. 啟用 Google Drive API\n4. 創建 OAuth2 憑據（類型：電視和受限輸入設備）\n5. 下載憑據文件並保存為上述路徑",
+		msgCredentialsOpened: "✓ 已在瀏覽器中打開 Google Cloud Console",
+		msgCredentialsManual: "提示：請手動訪問：",
+	},
+	LanguageEnglish: {
+		messageKey(CodeDisabled):              "Google Drive module is disabled",
+		messageKey(CodeMissingCredentials):    "credentials file path is required",
+		messageKey(CodeMissingTokenFile):      "token file path is required",
+		messageKey(CodeMissingFolderName):     "either FolderName or FolderID is required",
+		messageKey(CodeInvalidAuthFlow):       "unsupported auth flow: %s",
+		messageKey(CodeInvalidLanguage):       "unsupported language: %s",
+		messageKey(CodeInvalidBackupInterval): "BackupInterval must be greater than 0",
+		messageKey(CodeMissingBackupPaths):    "BackupPaths must not be empty",
+		messageKey(CodeInvalidQuotaPolicy):    "unsupported quota policy: %s",
+		messageKey(CodeSharedDriveAppData):    "shared drives cannot be used with only the drive.appdata scope, add drive or drive.file",
+
+		messageKey(CodeReadCredentials):        "cannot read credentials file",
+		messageKey(CodeParseCredentials):       "cannot parse credentials file",
+		messageKey(CodeInvalidCredentials):     "invalid credentials file: use an OAuth2 client of type \"TVs and Limited Input devices\" or \"Desktop app\", or a service account key",
+		messageKey(CodeParseServiceAccount):    "cannot parse service account key",
+		messageKey(CodeDeviceAuthFailed):       "device authorization failed",
+		messageKey(CodeDeviceCodeFailed):       "cannot get device code",
+		messageKey(CodeDeviceScopeUnsupported): "device flow does not support the requested scopes %s: Google only allows non-sensitive scopes such as drive.file and drive.appdata; adjust Scopes, or use the loopback flow (AuthFlowLoopback) or a service account",
+		messageKey(CodeLoopbackAuthFailed):     "browser authorization failed",
+		messageKey(CodeLoopbackListenFailed):   "cannot start local callback server",
+		messageKey(CodeAuthDenied):             "authorization denied or failed: %s",
+		messageKey(CodeMissingAuthCode):        "authorization callback is missing the code",
+		messageKey(CodeAuthTimeout):            "authorization timed out or failed",
+		messageKey(CodeTokenExchangeFailed):    "token exchange failed",
+		messageKey(CodeSaveTokenFailed):        "failed to save token",
+		messageKey(CodeGetTokenFailed):         "failed to get token",
+		messageKey(CodeDeleteTokenFailed):      "failed to delete token",
+		messageKey(CodeRevokeTokenFailed):      "failed to revoke token (local token deleted)",
+		messageKey(CodeUnauthorized):           "authorization is no longer valid, re-authorization required",
+		messageKey(CodeLoggedOut):              "logged out",
+		messageKey(CodeLogoutUnsupported):      "logout is not supported in the current auth mode",
+		messageKey(CodeReauthUnsupported):      "re-authorization is not supported in the current auth mode",
+		messageKey(CodeReauthCanceled):         "re-authorization canceled",
+		messageKey(CodeInsufficientScope):      "token lacks the required scopes",
+		messageKey(CodeMissingTokenSource):     "no TokenSource is configured",
+		messageKey(CodeRevokeRejected):         "revocation endpoint returned %d: %s",
+
+		messageKey(CodeNilToken):               "token must not be nil",
+		messageKey(CodeTokenNotFound):          "no token has been saved",
+		messageKey(CodeTokenExpired):           "token has expired and cannot be refreshed",
+		messageKey(CodeLoadTokenFailed):        "failed to load token",
+		messageKey(CodeParseTokenFile):         "failed to parse token file",
+		messageKey(CodeWriteTokenFile):         "failed to write token file",
+		messageKey(CodeTokenEncrypted):         "token file is encrypted but no decryption key is configured",
+		messageKey(CodeEncryptTokenFailed):     "failed to encrypt token",
+		messageKey(CodeDecryptTokenFailed):     "failed to decrypt token (wrong key or corrupted file)",
+		messageKey(CodeUnsupportedTokenFormat): "unsupported encrypted token format: %s/%s",
+		messageKey(CodeInvalidTokenIterations): "invalid iteration count in encrypted token file: %d",
+		messageKey(CodeCorruptTokenFile):       "encrypted token file is corrupted",
+		messageKey(CodeMissingEncryptionKey):   "no token encryption key is configured",
+		messageKey(CodeMissingPassphraseEnv):   "environment variable %s is not set or empty",
+		messageKey(CodeReadKeyFile):            "failed to read key file",
+		messageKey(CodeEmptyKeyFile):           "key file is empty: %s",
+		messageKey(CodeDeriveKeyFailed):        "failed to derive key",
+		messageKey(CodeRandomFailed):           "failed to generate random bytes",
+
+		messageKey(CodeAuthFailed):           "authentication failed",
+		messageKey(CodeCreateServiceFailed):  "failed to create Drive service",
+		messageKey(CodeInitFolderFailed):     "failed to initialize folder",
+		messageKey(CodeAboutFailed):          "failed to get account info",
+		messageKey(CodeQuotaFailed):          "failed to get storage quota",
+		messageKey(CodeListDrivesFailed):     "failed to list shared drives",
+		messageKey(CodeSharedDriveNotFound):  "shared drive not found: %s",
+		messageKey(CodeSharedDriveAmbiguous): "multiple shared drives are named %s, use SharedDriveID instead",
+		messageKey(CodeBackupDisabled):       "backup is not enabled, set BackupEnabled = true in the config",
+		messageKey(CodeBackupRunning):        "backup is already running",
+
+		messageKey(CodeOpenLocalFile):         "cannot open local file",
+		messageKey(CodeUploadFailed):          "failed to upload file",
+		messageKey(CodeUpdateFailed):          "failed to update file",
+		messageKey(CodeFindFileFailed):        "failed to look up file",
+		messageKey(CodeQueryFilesFailed):      "failed to query files",
+		messageKey(CodeFileNotFound):          "file not found: %s",
+		messageKey(CodeCreateFolderFailed):    "failed to create folder",
+		messageKey(CodeQueryFolderFailed):     "failed to query folders",
+		messageKey(CodeFolderNotFound):        "folder not found: %s",
+		messageKey(CodeInvalidFolderPath):     "invalid folder path: %q",
+		messageKey(CodeDownloadFailed):        "failed to download file",
+		messageKey(CodeWriteLocalFile):        "cannot write local file",
+		messageKey(CodeChecksumMismatch):      "checksum mismatch for %s (expected %s, got %s)",
+		messageKey(CodeSaveSHA256Failed):      "failed to save SHA-256 of file %s",
+		messageKey(CodeMissingUploadLocation): "upload session response is missing the session location (Location header)",
+
+		msgRetry:             "%s, retrying in %v (%d/%d): %v",
+		msgAPIError:          "Drive API error %d: %s",
+		msgAPIErrorReason:    "Drive API error %d (%s): %s",
+		msgScopesMissing:     "saved token is missing scopes %s, re-authorization required",
+		msgScopeInsufficient: "Drive reported insufficient scopes (403), call Reauthorize or configure OnReauth",
+		msgLoopbackURL:       "open the following URL in a browser to authorize Google Drive: %s",
+		msgTokenRefreshed:    "Google Drive token refreshed, expires at: %s",
+		msgTokenSaveFailed:   "failed to save refreshed token: %v",
+		msgAuthInvalidated:   "Google Drive authorization is no longer valid (refresh token revoked or expired): %v",
+		msgReauthFailed:      "Google Drive re-authorization failed: %v",
+		msgReauthSucceeded:   "Google Drive re-authorization succeeded",
+		msgBackupStarted:     "scheduled backup started, interval: %v",
+		msgBackupStopped:     "scheduled backup stopped",
+		msgBackupSkipped:     "backup run skipped: %v",
+		msgBackupRunning:     "backup run started",
+		msgBackupScanFailed:  "failed to scan files: %v",
+		msgBackupNothing:     "no files to back up",
+		msgBackupCanceled:    "backup run canceled",
+		msgBackupStatFailed:  "cannot access file %s: %v",
+		msgBackupAborted:     "backup run aborted: %v",
+		msgBackupFileFailed:  "backup failed %s: %v",
+		msgBackupCreated:     "created: %s",
+		msgBackupUpdated:     "updated: %s",
+		msgBackupUnchanged:   "unchanged, skipped: %s",
+		msgBackupSummary:     "backup run finished - succeeded: %d, unchanged: %d, failed: %d",
+		msgBackupPathFailed:  "cannot access path %s: %v",
+		msgBackupWalkFailed:  "failed to scan directory %s: %v",
+		msgQuotaCheckFailed:  "quota check skipped: %v",
+		msgQuotaWarning:      "planned upload %s exceeds remaining quota %s, uploads may fail",
+		msgQuotaDeferred:     "insufficient quota (planned %s, remaining %s), %d files deferred to the next run",
+		msgQuotaDeferredFile: "deferred: %s (%s)",
+		msgUploadResumed:     "resuming upload of %s (%s / %s)",
+		msgUploadExpired:     "upload session expired, restarting: %s",
+		msgJournalFailed:     "failed to access upload journal: %v",
+		msgTransferProgress:  "%s: %s / %s (%s/s, ETA %s)",
+		msgTransferUnknown:   "%s: %s (%s/s)",
+		msgBackupProgress:    "backup progress: %d / %d files, %s / %s (%s/s, ETA %s)",
+		msgDeviceTitle:       "Google Drive device authorization",
+		msgDeviceBrowser:     "1. The authorization page has been opened in your browser",
+		msgDeviceOpenURL:     "1. Open the following URL in a browser",
+		msgDeviceURL:         "2. URL: %s",
+		msgDeviceUserCode:    "3. Enter the code: %s",
+		msgDeviceExpiry:      "4. The code expires at: %s",
+		msgWaitingAuth:       "Waiting for authorization...",
+		msgDeviceAuthorized:  "Google Drive device authorization succeeded",
+		msgLoopbackTitle:     "Google Drive browser authorization",
+		msgLoopbackBrowser:   "The authorization page has been opened in your browser. If it did not open, visit:",
+		msgLoopbackOpenURL:   "Open the following URL in a browser:",
+		msgLoopbackDone:      "Google Drive browser authorization succeeded",
+		msgLoopbackSuccess:   "Google Drive authorization succeeded",
+		msgLoopbackFailure:   "Google Drive authorization failed",
+		msgLoopbackState:     "state parameter mismatch",
+		msgLoopbackClose:     "You can close this page and return to the application.",
+		msgCredentialsTitle:  "Credentials file not found or invalid",
+		msgCredentialsPath:   "Expected path: %s",
+		msgCredentialsSteps:  "To create a credentials file:\n\n1. Open the Google Cloud Console\n2. Create or select a project\n3. Enable the Google Drive API\n4. Create OAuth2 credentials (type: TVs and Limited Input devices)\n5. Download the credentials file and save it to the path above",
+		msgCredentialsOpened: "Google Cloud Console has been opened in your browser",
+		msgCredentialsManual: "Visit manually:",
+	},
+}
+
+// valid 判斷是否為支持的語言
+func (l Language) valid() bool {
+	_, ok := catalog[l]
+	return ok
+}
+
+// text 返回指定語言的消息格式（缺失時回退到繁體中文）
+func (l Language) text(key messageKey) string {
+	if message, ok := catalog[l][key]; ok {
+		return message
+	}
+	if message, ok := catalog[LanguageTraditionalChinese][key]; ok {
+		return message
+	}
+	return string(key)
+}
+
+// language 返回配置的語言（未設置時使用繁體中文）
+func (c *Config) language() Language {
+	if c.Language == "" {
+		return LanguageTraditionalChinese
+	}
+	return c.Language
+}
+
+// text 返回配置語言的消息格式
+func (c *Config) text(key messageKey) string {
+	return c.language().text(key)
+}
+
+// newError 創建帶錯誤代碼的本地化錯誤
+// err: 原始錯誤（可為 nil）；args: 消息格式參數
+func (c *Config) newError(code ErrorCode, err error, args ...interface{}) error {
+	return c.language().newError(code, err, args...)
+}
+
+// newError 創建指定語言的帶錯誤代碼的錯誤（用於無法訪問 Config 的場景，如 Token 存儲）
+func (l Language) newError(code ErrorCode, err error, args ...interface{}) error {
+	return &Error{
+		Code:    code,
+		Message: fmt.Sprintf(l.text(messageKey(code)), args...),
+		Err:     err,
+	}
+}
@@ -0,0 +1,45 @@
+package gdrive
+
+import (
+	"strings"
+	"testing"
+	"unicode"
+)
+
+func TestCatalogComplete(t *testing.T) {
+	zh, en := catalog[LanguageTraditionalChinese], catalog[LanguageEnglish]
+	for key, message := range zh {
+		translated, ok := en[key]
+		if !ok {
+			t.Errorf("%s: 缺少英文消息", key)
+			continue
+		}
+		if strings.Count(message, "%")-strings.Count(message, "%%") != strings.Count(translated, "%")-strings.Count(translated, "%%") {
+			t.Errorf("%s: 格式參數數量不一致: %q / %q", key, message, translated)
+		}
+		for _, r := range translated {
+			if unicode.Is(unicode.Han, r) {
+				t.Errorf("%s: 英文消息包含中文: %q", key, translated)
+				break
+			}
+		}
+	}
+	for key := range en {
+		if _, ok := zh[key]; !ok {
+			t.Errorf("%s: 缺少繁體中文消息", key)
+		}
+	}
+}
+
+func TestLanguageNewError(t *testing.T) {
+	err := LanguageEnglish.newError(CodeEmptyKeyFile, nil, "/etc/key")
+	if ErrorCodeOf(err) != CodeEmptyKeyFile || err.Error() != "key file is empty: /etc/key" {
+		t.Fatalf("err = %v", err)
+	}
+
+	// 未設置語言時使用繁體中文
+	var unset Language
+	if err := unset.newError(CodeNilToken, nil); err.Error() != "Token 不能為空" {
+		t.Fatalf("err = %v", err)
+	}
+}
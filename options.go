@@ -0,0 +1,101 @@
+package gdrive
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+
+	"golang.org/x/oauth2"
+	"google.golang.org/api/drive/v3"
+)
+
+// Option NewClient 的可選配置
+type Option func(*clientOptions)
+
+// clientOptions 客戶端可選配置
+type clientOptions struct {
+	httpClient  *http.Client       // 底層 HTTP 客戶端
+	tokenSource oauth2.TokenSource // 外部提供的 TokenSource
+	endpoint    string             // Drive API 端點
+	userAgent   string             // User-Agent
+	service     *drive.Service     // 外部提供的 Drive Service
+}
+
+// WithHTTPClient 指定底層 HTTP 客戶端，用於配置代理、自定義 TLS 根證書、超時等
+// 授權請求（Token 刷新、Device Flow、撤銷）和 Drive API 請求都會使用該客戶端的 Transport
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(o *clientOptions) {
+		o.httpClient = httpClient
+	}
+}
+
+// WithTokenSource 使用外部提供的 TokenSource，跳過憑據文件和交互式授權流程
+// 此時不需要配置 CredentialsFile / TokenFile，Logout 和 Reauthorize 不可用
+func WithTokenSource(tokenSource oauth2.TokenSource) Option {
+	return func(o *clientOptions) {
+		o.tokenSource = tokenSource
+	}
+}
+
+// WithEndpoint 指定 Drive API 端點（如本地模擬服務 "http://127.0.0.1:8080/"）
+// 未包含路徑時自動補全為 "/drive/v3/"
+func WithEndpoint(endpoint string) Option {
+	return func(o *clientOptions) {
+		o.endpoint = normalizeEndpoint(endpoint)
+	}
+}
+
+// WithUserAgent 指定 Drive API 請求的 User-Agent
+func WithUserAgent(userAgent string) Option {
+	return func(o *clientOptions) {
+		o.userAgent = userAgent
+	}
+}
+
+// WithDriveService 直接使用外部創建的 Drive Service，跳過授權
+// 此時 WithHTTPClient、WithEndpoint、WithUserAgent 對 Drive API 請求無效
+func WithDriveService(service *drive.Service) Option {
+	return func(o *clientOptions) {
+		o.service = service
+	}
+}
+
+// newClientOptions 應用可選配置
+func newClientOptions(opts []Option) *clientOptions {
+	options := &clientOptions{}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(options)
+		}
+	}
+	return options
+}
+
+// requiresCredentials 判斷是否需要憑據文件
+func (o *clientOptions) requiresCredentials() bool {
+	return o.tokenSource == nil && o.service == nil
+}
+
+// baseHTTPClient 返回底層 HTTP 客戶端（未指定時使用默認客戶端）
+func (o *clientOptions) baseHTTPClient() *http.Client {
+	if o.httpClient != nil {
+		return o.httpClient
+	}
+	return http.DefaultClient
+}
+
+// normalizeEndpoint 規範化 Drive API 端點
+func normalizeEndpoint(endpoint string) string {
+	if endpoint == "" {
+		return ""
+	}
+
+	parsed, err := url.Parse(endpoint)
+	if err == nil && (parsed.Path == "" || parsed.Path == "/") {
+		return strings.TrimSuffix(endpoint, "/") + "/drive/v3/"
+	}
+	if !strings.HasSuffix(endpoint, "/") {
+		endpoint += "/"
+	}
+	return endpoint
+}
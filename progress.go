@@ -0,0 +1,360 @@
+package gdrive
+
+import (
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+// 進度報告間隔
+const (
+	defaultProgressInterval = 500 * time.Millisecond // 進度回調的默認最小間隔
+	progressLogInterval     = 10 * time.Second       // 進度日志的間隔（耗時不足該間隔的傳輸不輸出進度日志）
+)
+
+// Progress 單個文件的傳輸進度（上傳或下載）
+type Progress struct {
+	Name       string        // 文件名
+	BytesDone  int64         // 已傳輸字節數
+	BytesTotal int64         // 總字節數（未知時為 -1）
+	Rate       float64       // 平均傳輸速率（字節/秒）
+	ETA        time.Duration // 預計剩餘時間（未知時為 -1）
+}
+
+// ProgressFunc 傳輸進度回調，可能在 HTTP 客戶端內部的 goroutine 中同步調用，應盡快返回
+type ProgressFunc func(Progress)
+
+// BackupProgress 一次備份任務的整體進度
+type BackupProgress struct {
+	FilesDone   int           // 已處理的文件數（包括失敗的文件）
+	FilesTotal  int           // 本次計劃備份的文件數（不包括內容未變而跳過的文件）
+	BytesDone   int64         // 已處理的字節數
+	BytesTotal  int64         // 本次計劃備份的總字節數
+	Rate        float64       // 平均傳輸速率（字節/秒）
+	ETA         time.Duration // 預計剩餘時間（未知時為 -1）
+	CurrentFile string        // 正在上傳的文件（處理完所有文件後為空）
+
+	// Deferred 因存儲空間不足推遲到下次備份的文件（QuotaPolicyDefer），不計入 FilesTotal 和 BytesTotal
+	// 同一次備份的各次回調共用該切片，不應修改
+	Deferred []string
+}
+
+// BackupProgressFunc 備份進度回調，同步調用，應盡快返回
+type BackupProgressFunc func(BackupProgress)
+
+// progressInterval 返回進度回調的最小間隔
+func (c *Config) progressInterval() time.Duration {
+	if c.ProgressInterval > 0 {
+		return c.ProgressInterval
+	}
+	return defaultProgressInterval
+}
+
+// rateAndETA 根據本次已傳輸量和耗時計算平均速率和預計剩餘時間
+func rateAndETA(transferred, remaining int64, elapsed time.Duration) (float64, time.Duration) {
+	if elapsed <= 0 || transferred <= 0 {
+		return 0, -1
+	}
+	rate := float64(transferred) / elapsed.Seconds()
+	if remaining < 0 {
+		return rate, -1
+	}
+	return rate, time.Duration(float64(remaining) / rate * float64(time.Second))
+}
+
+// transferProgress 跟蹤單個文件的傳輸進度，按間隔調用回調並輸出進度日志
+type transferProgress struct {
+	config    *Config
+	name      string
+	total     int64
+	callbacks []ProgressFunc
+
+	mu         sync.Mutex
+	start      time.Time
+	base       int64 // 開始時已完成的字節數（斷點續傳時不計入速率）
+	done       int64
+	lastReport time.Time
+	lastLog    time.Time
+}
+
+// newTransferProgress 創建傳輸進度跟蹤器
+// total 未知時為 -1；callbacks 中的 nil 會被忽略，Config.OnProgress 總是會被調用
+func (c *Config) newTransferProgress(name string, total int64, callbacks ...ProgressFunc) *transferProgress {
+	p := &transferProgress{
+		config: c,
+		name:   name,
+		total:  total,
+		start:  time.Now(),
+	}
+	p.lastLog = p.start
+	for _, fn := range append([]ProgressFunc{c.OnProgress}, callbacks...) {
+		if fn != nil {
+			p.callbacks = append(p.callbacks, fn)
+		}
+	}
+	return p
+}
+
+// resume 從斷點繼續時設置已完成的字節數（不計入速率）
+func (p *transferProgress) resume(done int64) {
+	p.mu.Lock()
+	p.base, p.done = done, done
+	p.start = time.Now()
+	p.mu.Unlock()
+}
+
+// set 設置已完成的字節數（重試時可能回退）
+func (p *transferProgress) set(done int64) {
+	p.mu.Lock()
+	p.done = done
+	p.mu.Unlock()
+	p.report(false)
+}
+
+// add 增加已完成的字節數
+func (p *transferProgress) add(n int64) {
+	p.mu.Lock()
+	p.done += n
+	p.mu.Unlock()
+	p.report(false)
+}
+
+// finish 傳輸完成時報告最終進度
+func (p *transferProgress) finish() {
+	p.mu.Lock()
+	if p.total < 0 {
+		p.total = p.done
+	}
+	p.mu.Unlock()
+	p.report(true)
+}
+
+// snapshot 返回當前進度
+func (p *transferProgress) snapshot() Progress {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	remaining := int64(-1)
+	if p.total >= 0 {
+		remaining = max(p.total-p.done, 0)
+	}
+	rate, eta := rateAndETA(p.done-p.base, remaining, time.Since(p.start))
+	return Progress{
+		Name:       p.name,
+		BytesDone:  p.done,
+		BytesTotal: p.total,
+		Rate:       rate,
+		ETA:        eta,
+	}
+}
+
+// report 按間隔調用回調和輸出進度日志，force 為 true 時總是調用回調
+func (p *transferProgress) report(force bool) {
+	now := time.Now()
+
+	p.mu.Lock()
+	notify := len(p.callbacks) > 0 && (force || now.Sub(p.lastReport) >= p.config.progressInterval())
+	if notify {
+		p.lastReport = now
+	}
+	log := !force && now.Sub(p.lastLog) >= progressLogInterval
+	if log {
+		p.lastLog = now
+	}
+	p.mu.Unlock()
+
+	if !notify && !log {
+		return
+	}
+
+	progress := p.snapshot()
+	if notify {
+		for _, fn := range p.callbacks {
+			fn(progress)
+		}
+	}
+	if log {
+		p.config.logProgress(progress)
+	}
+}
+
+// logProgress 輸出單個文件的傳輸進度日志
+func (c *Config) logProgress(p Progress) {
+	if p.BytesTotal < 0 {
+		c.logger().Infof(c.text(msgTransferUnknown), p.Name, formatBytes(p.BytesDone), formatBytes(int64(p.Rate)))
+		return
+	}
+	c.logger().Infof(c.text(msgTransferProgress), p.Name, formatBytes(p.BytesDone), formatBytes(p.BytesTotal),
+		formatBytes(int64(p.Rate)), formatETA(p.ETA))
+}
+
+// formatETA 格式化預計剩餘時間（未知時為 "-"）
+func formatETA(eta time.Duration) string {
+	if eta < 0 {
+		return "-"
+	}
+	return eta.Round(time.Second).String()
+}
+
+// reader 返回讀取時報告進度的 io.Reader
+func (p *transferProgress) reader(r io.Reader) io.Reader {
+	return &progressReader{r: r, progress: p}
+}
+
+// writer 返回寫入時報告進度的 io.Writer
+func (p *transferProgress) writer() io.Writer {
+	return progressWriter{progress: p}
+}
+
+// progressReader 讀取時報告進度
+type progressReader struct {
+	r        io.Reader
+	progress *transferProgress
+}
+
+// Read 實現 io.Reader
+func (r *progressReader) Read(b []byte) (int, error) {
+	n, err := r.r.Read(b)
+	if n > 0 {
+		r.progress.add(int64(n))
+	}
+	return n, err
+}
+
+// progressWriter 寫入時報告進度
+type progressWriter struct {
+	progress *transferProgress
+}
+
+// Write 實現 io.Writer
+func (w progressWriter) Write(b []byte) (int, error) {
+	w.progress.add(int64(len(b)))
+	return len(b), nil
+}
+
+// readerSize 返回 r 剩餘內容的大小，無法確定時返回 -1
+func readerSize(r io.Reader) int64 {
+	switch v := r.(type) {
+	case interface{ Len() int }:
+		return int64(v.Len())
+	case *os.File:
+		info, err := v.Stat()
+		if err != nil || !info.Mode().IsRegular() {
+			return -1
+		}
+		offset, err := v.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return -1
+		}
+		return info.Size() - offset
+	default:
+		return -1
+	}
+}
+
+// runProgress 跟蹤一次備份任務的整體進度
+type runProgress struct {
+	config     *Config
+	start      time.Time
+	filesTotal int
+	bytesTotal int64
+	deferred   []string
+
+	mu          sync.Mutex
+	filesDone   int
+	bytesDone   int64 // 已處理文件的字節數
+	current     string
+	currentDone int64 // 當前文件已傳輸的字節數
+	lastReport  time.Time
+	lastLog     time.Time
+}
+
+// newRunProgress 創建備份進度跟蹤器
+// deferred: 因存儲空間不足推遲的文件
+func (c *Config) newRunProgress(planned, deferred []plannedFile) *runProgress {
+	p := &runProgress{
+		config:     c,
+		start:      time.Now(),
+		filesTotal: len(planned),
+	}
+	p.lastLog = p.start
+	for _, f := range planned {
+		p.bytesTotal += f.info.Size()
+	}
+	for _, f := range deferred {
+		p.deferred = append(p.deferred, f.path)
+	}
+	return p
+}
+
+// startFile 開始上傳文件
+func (p *runProgress) startFile(path string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.current = path
+	p.currentDone = 0
+}
+
+// fileProgress 返回當前文件的進度回調
+func (p *runProgress) fileProgress() ProgressFunc {
+	return func(progress Progress) {
+		p.mu.Lock()
+		p.currentDone = progress.BytesDone
+		p.mu.Unlock()
+		p.report(false)
+	}
+}
+
+// finishFile 文件處理完成（成功或失敗）
+func (p *runProgress) finishFile(size int64) {
+	p.mu.Lock()
+	p.filesDone++
+	p.bytesDone += size
+	p.current = ""
+	p.currentDone = 0
+	p.mu.Unlock()
+	p.report(true)
+}
+
+// snapshot 返回當前進度（調用方需持有 p.mu）
+func (p *runProgress) snapshot() BackupProgress {
+	done := min(p.bytesDone+p.currentDone, p.bytesTotal)
+	rate, eta := rateAndETA(done, p.bytesTotal-done, time.Since(p.start))
+	return BackupProgress{
+		FilesDone:   p.filesDone,
+		FilesTotal:  p.filesTotal,
+		BytesDone:   done,
+		BytesTotal:  p.bytesTotal,
+		Rate:        rate,
+		ETA:         eta,
+		CurrentFile: p.current,
+		Deferred:    p.deferred,
+	}
+}
+
+// report 按間隔調用回調和輸出進度日志，force 為 true 時總是調用回調
+func (p *runProgress) report(force bool) {
+	now := time.Now()
+	fn := p.config.OnBackupProgress
+
+	p.mu.Lock()
+	notify := fn != nil && (force || now.Sub(p.lastReport) >= p.config.progressInterval())
+	if notify {
+		p.lastReport = now
+	}
+	log := now.Sub(p.lastLog) >= progressLogInterval
+	if log {
+		p.lastLog = now
+	}
+	progress := p.snapshot()
+	p.mu.Unlock()
+
+	if notify {
+		fn(progress)
+	}
+	if log {
+		p.config.logger().Infof(p.config.text(msgBackupProgress), progress.FilesDone, progress.FilesTotal,
+			formatBytes(progress.BytesDone), formatBytes(progress.BytesTotal), formatBytes(int64(progress.Rate)), formatETA(progress.ETA))
+	}
+}
@@ -0,0 +1,143 @@
+package gdrive_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"testing/iotest"
+	"time"
+
+	"github.com/Digman/gdrive"
+	"github.com/Digman/gdrive/gdrivetest"
+)
+
+// progressRecorder 記錄傳輸進度回調
+type progressRecorder struct {
+	mu    sync.Mutex
+	calls []gdrive.Progress
+}
+
+func (r *progressRecorder) record(p gdrive.Progress) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.calls = append(r.calls, p)
+}
+
+func (r *progressRecorder) progress() []gdrive.Progress {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]gdrive.Progress(nil), r.calls...)
+}
+
+// assertFinished 檢查最後一次回調報告傳輸完成，且只有這一次報告完成
+func assertFinished(t *testing.T, calls []gdrive.Progress, size int64) {
+	t.Helper()
+
+	if len(calls) == 0 {
+		t.Fatal("no progress callbacks")
+	}
+	last := calls[len(calls)-1]
+	if last.BytesDone != size || last.BytesTotal != size || last.ETA != 0 {
+		t.Fatalf("final progress = %+v, want %d/%d bytes and ETA 0", last, size, size)
+	}
+	for _, p := range calls[:len(calls)-1] {
+		if p.BytesDone == size && p.BytesTotal == size {
+			t.Fatalf("progress = %+v, want only the final callback to report completion", calls)
+		}
+	}
+}
+
+func TestUploadProgressSmallFile(t *testing.T) {
+	srv := gdrivetest.NewServer()
+	defer srv.Close()
+	client := newTestClient(t, srv, nil, func(c *gdrive.Config) { c.ProgressInterval = time.Hour })
+
+	// 間隔內不重複回調，完成時總是回調一次
+	var recorder progressRecorder
+	content := []byte("hello, drive")
+	if _, err := client.UploadReader(context.Background(), "a.txt", bytes.NewReader(content),
+		&gdrive.UploadOptions{OnProgress: recorder.record}); err != nil {
+		t.Fatalf("UploadReader: %v", err)
+	}
+	calls := recorder.progress()
+	assertFinished(t, calls, int64(len(content)))
+	if calls[len(calls)-1].Name != "a.txt" {
+		t.Fatalf("Name = %q, want a.txt", calls[len(calls)-1].Name)
+	}
+}
+
+func TestUploadProgressResumed(t *testing.T) {
+	srv := gdrivetest.NewServer()
+	defer srv.Close()
+
+	journal := filepath.Join(t.TempDir(), "journal.json")
+	content := bytes.Repeat([]byte("0123456789abcdef"), 700<<10/16)
+	localPath := writeTempFile(t, "big.bin", content)
+
+	// 第一個分塊上傳後網絡中斷
+	interrupted := &chunkTransport{
+		base: srv.HTTPClient().Transport,
+		fail: func(r *http.Request) bool {
+			return r.Method == http.MethodPut && !strings.HasPrefix(r.Header.Get("Content-Range"), "bytes 0-")
+		},
+	}
+	client := newTestClient(t, srv, interrupted, withJournal(journal))
+	if _, err := client.UploadFile(localPath); err == nil {
+		t.Fatal("UploadFile should fail")
+	}
+
+	// 從日志續傳：進度從已上傳的偏移開始，已上傳的部分不計入速率
+	var recorder progressRecorder
+	client = newTestClient(t, srv, nil, func(c *gdrive.Config) {
+		withJournal(journal)(c)
+		c.ProgressInterval = time.Hour
+		c.OnProgress = recorder.record
+	})
+	if _, err := client.UploadFile(localPath); err != nil {
+		t.Fatalf("UploadFile: %v", err)
+	}
+
+	const offset = 256 << 10
+	calls := recorder.progress()
+	assertFinished(t, calls, int64(len(content)))
+	if first := calls[0]; first.BytesDone != offset || first.Rate != 0 || first.ETA != -1 {
+		t.Fatalf("first progress = %+v, want %d bytes done with no rate", first, offset)
+	}
+	for _, p := range calls {
+		if p.BytesDone < offset {
+			t.Fatalf("progress = %+v, want at least %d bytes done", p, offset)
+		}
+	}
+}
+
+func TestUploadProgressUnknownLength(t *testing.T) {
+	srv := gdrivetest.NewServer()
+	defer srv.Close()
+	client := newTestClient(t, srv, nil, func(c *gdrive.Config) { c.ProgressInterval = time.Nanosecond })
+
+	// 不實現 Len 的 reader 長度未知，傳輸中總字節數和剩餘時間都為 -1
+	var recorder progressRecorder
+	content := bytes.Repeat([]byte("x"), 4<<10)
+	r := iotest.HalfReader(io.MultiReader(bytes.NewReader(content)))
+	if _, err := client.UploadReader(context.Background(), "a.txt", r,
+		&gdrive.UploadOptions{OnProgress: recorder.record}); err != nil {
+		t.Fatalf("UploadReader: %v", err)
+	}
+
+	calls := recorder.progress()
+	assertFinished(t, calls, int64(len(content)))
+	running := calls[:len(calls)-1]
+	if len(running) == 0 {
+		t.Fatal("no progress callbacks while uploading")
+	}
+	for _, p := range running {
+		if p.BytesTotal != -1 || p.ETA != -1 {
+			t.Fatalf("progress = %+v, want unknown total and ETA", p)
+		}
+	}
+}
@@ -0,0 +1,171 @@
+package gdrive
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+)
+
+// credentialsConsoleURL Google Cloud Console 憑據頁面
+const credentialsConsoleURL = "https://console.cloud.google.com/apis/credentials"
+
+// DeviceCode Device Flow 授權信息
+type DeviceCode struct {
+	VerificationURI         string    // 授權網址
+	VerificationURIComplete string    // 已包含授權碼的網址（可能為空）
+	UserCode                string    // 用戶需要輸入的授權碼
+	Expiry                  time.Time // 授權碼過期時間（零值表示未知）
+	BrowserOpened           bool      // 是否已自動打開瀏覽器
+}
+
+// CredentialsGuide 憑據文件缺失時的設置指引信息
+type CredentialsGuide struct {
+	CredentialsPath string // 預期的憑據文件路徑
+	ConsoleURL      string // Google Cloud Console 憑據頁面網址
+	BrowserOpened   bool   // 是否已自動打開瀏覽器
+}
+
+// DevicePrompter 授權交互接口，用於向用戶展示授權信息
+// 默認實現 TerminalPrompter 輸出到終端；GUI 應用或守護進程可自定義實現
+type DevicePrompter interface {
+	// PromptDeviceCode 展示 Device Flow 授權網址和授權碼
+	PromptDeviceCode(code DeviceCode)
+
+	// DeviceAuthorized 用戶已完成授權
+	DeviceAuthorized()
+
+	// PromptCredentialsSetup 憑據文件缺失或無法解析時展示設置指引
+	PromptCredentialsSetup(guide CredentialsGuide)
+}
+
+// TerminalPrompter 終端授權交互實現（默認）
+type TerminalPrompter struct {
+	Out      io.Writer // 輸出目標（nil 則使用標準輸出）
+	Language Language  // 提示語言（空則使用繁體中文）
+}
+
+// NewTerminalPrompter 創建輸出到標準輸出的終端授權交互實例
+func NewTerminalPrompter() *TerminalPrompter {
+	return &TerminalPrompter{Out: os.Stdout}
+}
+
+// terminalRule 終端提示的分隔線
+const terminalRule = "━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━"
+
+// writer 返回輸出目標
+func (p *TerminalPrompter) writer() io.Writer {
+	if p.Out != nil {
+		return p.Out
+	}
+	return os.Stdout
+}
+
+// text 返回提示語言的消息格式
+func (p *TerminalPrompter) text(key messageKey) string {
+	return p.Language.text(key)
+}
+
+// printf 輸出一行縮進的提示
+func (p *TerminalPrompter) printf(key messageKey, args ...interface{}) {
+	for _, line := range strings.Split(fmt.Sprintf(p.text(key), args...), "\n") {
+		if line == "" {
+			fmt.Fprintln(p.writer())
+			continue
+		}
+		fmt.Fprintf(p.writer(), "  %s\n", line)
+	}
+}
+
+// printTitle 輸出帶分隔線的標題
+func (p *TerminalPrompter) printTitle(key messageKey) {
+	w := p.writer()
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, terminalRule)
+	p.printf(key)
+	fmt.Fprintln(w, terminalRule)
+	fmt.Fprintln(w)
+}
+
+// PromptDeviceCode 在終端顯示授權網址和授權碼
+func (p *TerminalPrompter) PromptDeviceCode(code DeviceCode) {
+	w := p.writer()
+	p.printTitle(msgDeviceTitle)
+	if code.BrowserOpened {
+		p.printf(msgDeviceBrowser)
+	} else {
+		p.printf(msgDeviceOpenURL)
+	}
+	p.printf(msgDeviceURL, code.VerificationURI)
+	p.printf(msgDeviceUserCode, "\033[1;36m"+code.UserCode+"\033[0m")
+	if !code.Expiry.IsZero() {
+		p.printf(msgDeviceExpiry, code.Expiry.Format("15:04:05"))
+	}
+	fmt.Fprintln(w)
+	p.printf(msgWaitingAuth)
+	fmt.Fprintln(w)
+}
+
+// DeviceAuthorized 在終端顯示授權成功
+func (p *TerminalPrompter) DeviceAuthorized() {
+	p.printf(msgDeviceAuthorized)
+	fmt.Fprintln(p.writer())
+}
+
+// PromptAuthURL 在終端顯示回環授權網址
+func (p *TerminalPrompter) PromptAuthURL(authURL string, browserOpened bool) {
+	w := p.writer()
+	p.printTitle(msgLoopbackTitle)
+	if browserOpened {
+		p.printf(msgLoopbackBrowser)
+	} else {
+		p.printf(msgLoopbackOpenURL)
+	}
+	fmt.Fprintf(w, "  %s\n", authURL)
+	fmt.Fprintln(w)
+	p.printf(msgWaitingAuth)
+	fmt.Fprintln(w)
+}
+
+// LoopbackAuthorized 在終端顯示回環授權成功
+func (p *TerminalPrompter) LoopbackAuthorized() {
+	p.printf(msgLoopbackDone)
+	fmt.Fprintln(p.writer())
+}
+
+// PromptCredentialsSetup 在終端顯示憑據設置指南
+func (p *TerminalPrompter) PromptCredentialsSetup(guide CredentialsGuide) {
+	w := p.writer()
+	p.printTitle(msgCredentialsTitle)
+	p.printf(msgCredentialsPath, guide.CredentialsPath)
+	fmt.Fprintln(w)
+	p.printf(msgCredentialsSteps)
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, terminalRule)
+	fmt.Fprintln(w)
+
+	if guide.BrowserOpened {
+		p.printf(msgCredentialsOpened)
+		fmt.Fprintln(w)
+	} else {
+		p.printf(msgCredentialsManual)
+		fmt.Fprintf(w, "  %s\n\n", guide.ConsoleURL)
+	}
+}
+
+// prompter 返回配置使用的授權交互實例（未設置時使用終端實現）
+func (c *Config) prompter() DevicePrompter {
+	if c.DevicePrompter != nil {
+		return c.DevicePrompter
+	}
+	return &TerminalPrompter{Out: os.Stdout, Language: c.Language}
+}
+
+// launchBrowser 按配置嘗試打開瀏覽器，返回是否成功
+func (c *Config) launchBrowser(url string) bool {
+	if c.DisableBrowser {
+		return false
+	}
+	return openBrowser(url) == nil
+}
@@ -0,0 +1,121 @@
+package gdrive
+
+import (
+	"strings"
+	"time"
+)
+
+// queryEscaper 轉義查詢字符串中的反斜杠和單引號
+var queryEscaper = strings.NewReplacer(`\`, `\\`, `'`, `\'`)
+
+// Query Drive 文件查詢條件（Files.List 的 q 參數）
+// 通過鏈式調用組合條件，多個條件之間為 and 關係，所有值都會被正確轉義：
+//
+//	q := gdrive.NewQuery().Name("O'Brien.txt").InParents(folderID).Trashed(false)
+//	q.String() // name = 'O\'Brien.txt' and 'folderID' in parents and trashed = false
+//
+// Query 是值類型，每次調用返回新的查詢，不會修改原查詢
+type Query struct {
+	expr string // 查詢表達式
+	op   string // 頂層邏輯運算符（"and"、"or"，單個條件時為空）
+}
+
+// NewQuery 創建空查詢（匹配所有文件）
+func NewQuery() Query {
+	return Query{}
+}
+
+// Name 文件名等於 name
+func (q Query) Name(name string) Query {
+	return q.And(term("name = " + quoteQuery(name)))
+}
+
+// NameContains 文件名包含 substr
+func (q Query) NameContains(substr string) Query {
+	return q.And(term("name contains " + quoteQuery(substr)))
+}
+
+// InParents 文件位於指定文件夾中
+func (q Query) InParents(folderID string) Query {
+	return q.And(term(quoteQuery(folderID) + " in parents"))
+}
+
+// MimeType 文件 MIME 類型等於 mimeType
+func (q Query) MimeType(mimeType string) Query {
+	return q.And(term("mimeType = " + quoteQuery(mimeType)))
+}
+
+// ModifiedAfter 文件修改時間晚於 t
+func (q Query) ModifiedAfter(t time.Time) Query {
+	return q.And(term("modifiedTime > " + quoteQuery(t.UTC().Format(time.RFC3339))))
+}
+
+// AppProperty 文件的應用私有屬性 key 等於 value
+func (q Query) AppProperty(key, value string) Query {
+	return q.And(term("appProperties has { key=" + quoteQuery(key) + " and value=" + quoteQuery(value) + " }"))
+}
+
+// Trashed 文件是否在回收站中
+func (q Query) Trashed(trashed bool) Query {
+	if trashed {
+		return q.And(term("trashed = true"))
+	}
+	return q.And(term("trashed = false"))
+}
+
+// And 返回當前查詢與 others 均滿足的查詢（空查詢會被忽略）
+func (q Query) And(others ...Query) Query {
+	return q.combine("and", others)
+}
+
+// Or 返回當前查詢與 others 任一滿足的查詢（空查詢會被忽略）
+func (q Query) Or(others ...Query) Query {
+	return q.combine("or", others)
+}
+
+// String 返回查詢表達式，可直接用作 Files.List 的 q 參數
+func (q Query) String() string {
+	return q.expr
+}
+
+// IsZero 判斷是否為空查詢
+func (q Query) IsZero() bool {
+	return q.expr == ""
+}
+
+// combine 使用邏輯運算符 op 組合查詢，必要時為子查詢加括號以保持優先級
+func (q Query) combine(op string, others []Query) Query {
+	var queries []Query
+	for _, part := range append([]Query{q}, others...) {
+		if !part.IsZero() {
+			queries = append(queries, part)
+		}
+	}
+	switch len(queries) {
+	case 0:
+		return Query{}
+	case 1:
+		// 只有一個非空查詢時保持原樣
+		return queries[0]
+	}
+
+	parts := make([]string, len(queries))
+	for i, part := range queries {
+		if part.op != "" && part.op != op {
+			parts[i] = "(" + part.expr + ")"
+		} else {
+			parts[i] = part.expr
+		}
+	}
+	return Query{expr: strings.Join(parts, " "+op+" "), op: op}
+}
+
+// term 創建單個條件的查詢
+func term(expr string) Query {
+	return Query{expr: expr}
+}
+
+// quoteQuery 將值轉義並加上單引號
+func quoteQuery(value string) string {
+	return "'" + queryEscaper.Replace(value) + "'"
+}
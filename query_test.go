@@ -0,0 +1,96 @@
+package gdrive_test
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/Digman/gdrive"
+	"github.com/Digman/gdrive/gdrivetest"
+)
+
+func TestQueryString(t *testing.T) {
+	tests := []struct {
+		name  string
+		query gdrive.Query
+		want  string
+	}{
+		{"empty", gdrive.NewQuery(), ""},
+		{"name", gdrive.NewQuery().Name("a.txt"), `name = 'a.txt'`},
+		{"quote", gdrive.NewQuery().Name("O'Brien.txt"), `name = 'O\'Brien.txt'`},
+		{"backslash", gdrive.NewQuery().Name(`C:\dir\a.txt`), `name = 'C:\\dir\\a.txt'`},
+		{"escaped quote", gdrive.NewQuery().Name(`it\'s`), `name = 'it\\\'s'`},
+		{"injection", gdrive.NewQuery().Name("x' or name contains '"), `name = 'x\' or name contains \''`},
+		{"in parents", gdrive.NewQuery().InParents("folder'1"), `'folder\'1' in parents`},
+		{"app property", gdrive.NewQuery().AppProperty("k'", `v\`), `appProperties has { key='k\'' and value='v\\' }`},
+		{"modified after", gdrive.NewQuery().ModifiedAfter(time.Date(2024, 1, 2, 3, 4, 5, 0, time.FixedZone("", 8*3600))),
+			`modifiedTime > '2024-01-01T19:04:05Z'`},
+		{"and", gdrive.NewQuery().Name("a").Trashed(false), `name = 'a' and trashed = false`},
+		{"or in and", gdrive.NewQuery().Name("a").Or(gdrive.NewQuery().Name("b")).Trashed(false),
+			`(name = 'a' or name = 'b') and trashed = false`},
+		{"ignore empty", gdrive.NewQuery().And(gdrive.NewQuery(), gdrive.NewQuery().Name("a")), `name = 'a'`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.query.String(); got != tt.want {
+				t.Fatalf("String() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSpecialCharacterNames(t *testing.T) {
+	names := []string{
+		"O'Brien.txt",
+		`back\slash.txt`,
+		`it\'s.txt`,
+		"x' or name contains '",
+	}
+
+	for _, name := range names {
+		t.Run(name, func(t *testing.T) {
+			srv := gdrivetest.NewServer()
+			defer srv.Close()
+			client, err := srv.NewClient(&gdrive.Config{FolderName: "O'Brien/" + name, Logger: testLogger{t}})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// 重複上傳應更新同一文件而不是創建新文件
+			localPath := writeTempFile(t, name, []byte("v1"))
+			fileID, created, err := client.UploadOrUpdateFile(localPath)
+			if err != nil || !created {
+				t.Fatalf("UploadOrUpdateFile = %v, %v, want created", created, err)
+			}
+			if err := os.WriteFile(localPath, []byte("v2"), 0644); err != nil {
+				t.Fatal(err)
+			}
+			updatedID, created, err := client.UploadOrUpdateFile(localPath)
+			if err != nil || created || updatedID != fileID {
+				t.Fatalf("UploadOrUpdateFile = %s, %v, %v, want update of %s", updatedID, created, err, fileID)
+			}
+
+			// 新客戶端沒有路徑緩存，按名稱逐級查找應得到同一文件夾
+			client2, err := srv.NewClient(&gdrive.Config{FolderName: "backups", Logger: testLogger{t}})
+			if err != nil {
+				t.Fatal(err)
+			}
+			folderID, err := client2.MkdirAll("O'Brien/" + name)
+			if err != nil {
+				t.Fatalf("MkdirAll: %v", err)
+			}
+			if n := len(srv.FindByName("O'Brien")); n != 1 {
+				t.Fatalf("folders named O'Brien = %d, want 1", n)
+			}
+
+			files, err := client2.FindFiles(gdrive.NewQuery().Name(name).InParents(folderID))
+			if err != nil {
+				t.Fatalf("FindFiles: %v", err)
+			}
+			if len(files) != 1 || files[0].ID != fileID {
+				t.Fatalf("FindFiles = %+v, want %s", files, fileID)
+			}
+		})
+	}
+}
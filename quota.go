@@ -0,0 +1,97 @@
+package gdrive
+
+import (
+	"context"
+	"fmt"
+
+	"google.golang.org/api/drive/v3"
+)
+
+// QuotaPolicy 備份前的存儲空間檢查策略
+type QuotaPolicy string
+
+const (
+	// QuotaPolicyWarn 計劃上傳量超出剩餘空間時僅輸出警告，仍上傳所有文件（默認）
+	QuotaPolicyWarn QuotaPolicy = "warn"
+
+	// QuotaPolicyDefer 計劃上傳量超出剩餘空間時，僅上傳放得下的文件，其餘推遲到下次備份
+	QuotaPolicyDefer QuotaPolicy = "defer"
+
+	// QuotaPolicyNone 不檢查存儲空間
+	QuotaPolicyNone QuotaPolicy = "none"
+)
+
+// Quota 存儲空間配額（單位：字節）
+type Quota struct {
+	Limit             int64 // 存儲空間上限（Unlimited 為 true 時為 0）
+	Usage             int64 // 已用空間（包括 Drive、Gmail、Google 相冊）
+	UsageInDrive      int64 // Drive 中文件的已用空間
+	UsageInDriveTrash int64 // Drive 回收站中文件的已用空間
+	Unlimited         bool  // 是否不限制存儲空間
+}
+
+// Remaining 返回剩餘空間，不限制存儲空間時返回 -1
+func (q *Quota) Remaining() int64 {
+	if q.Unlimited {
+		return -1
+	}
+	if q.Usage >= q.Limit {
+		return 0
+	}
+	return q.Limit - q.Usage
+}
+
+// Quota 獲取存儲空間配額
+// 返回: 配額信息和錯誤信息
+func (c *Client) Quota() (*Quota, error) {
+	return c.QuotaContext(context.Background())
+}
+
+// QuotaContext 獲取存儲空間配額（支持 context 取消）
+func (c *Client) QuotaContext(ctx context.Context) (*Quota, error) {
+	var about *drive.About
+	err := c.retry(ctx, CodeQuotaFailed, func() (err error) {
+		about, err = c.service.About.Get().
+			Fields("storageQuota").
+			Context(ctx).
+			Do()
+		return err
+	})
+	if err != nil {
+		return nil, c.config.newError(CodeQuotaFailed, err)
+	}
+
+	quota := &Quota{Unlimited: true}
+	if about.StorageQuota != nil {
+		quota.Limit = about.StorageQuota.Limit
+		quota.Usage = about.StorageQuota.Usage
+		quota.UsageInDrive = about.StorageQuota.UsageInDrive
+		quota.UsageInDriveTrash = about.StorageQuota.UsageInDriveTrash
+		// 未返回 limit 表示不限制存儲空間
+		quota.Unlimited = about.StorageQuota.Limit <= 0
+	}
+
+	return quota, nil
+}
+
+// quotaPolicy 返回備份前的存儲空間檢查策略（未設置時使用 QuotaPolicyWarn）
+func (c *Config) quotaPolicy() QuotaPolicy {
+	if c.BackupQuotaPolicy == "" {
+		return QuotaPolicyWarn
+	}
+	return c.BackupQuotaPolicy
+}
+
+// formatBytes 將字節數格式化為易讀的字符串
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
@@ -0,0 +1,365 @@
+package gdrive
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"google.golang.org/api/drive/v3"
+	"google.golang.org/api/googleapi"
+)
+
+// 分塊上傳參數
+const (
+	defaultUploadChunkSize = 8 << 20   // 默認分塊大小 8 MiB
+	uploadChunkAlign       = 256 << 10 // Drive 要求分塊大小為 256 KiB 的倍數
+)
+
+// uploadChunkSize 返回分塊大小（未設置時使用默認值，向上取整到 256 KiB 的倍數）
+func (c *Config) uploadChunkSize() int64 {
+	size := c.UploadChunkSize
+	if size <= 0 {
+		return defaultUploadChunkSize
+	}
+	if rem := size % uploadChunkAlign; rem != 0 {
+		size += uploadChunkAlign - rem
+	}
+	return size
+}
+
+// uploadLocalFile 上傳本地文件到 Drive
+// fileID 為空時在配置的文件夾中創建文件，否則更新該文件
+// 文件大於分塊大小時使用可續傳分塊上傳，配置了 UploadJournalFile 時中斷的上傳在重啓後從斷點繼續
+// onProgress: 本次上傳的額外進度回調（可為 nil）
+func (c *Client) uploadLocalFile(ctx context.Context, localPath, fileID string, onProgress ProgressFunc) (string, error) {
+	// 打開本地文件
+	file, err := os.Open(localPath)
+	if err != nil {
+		return "", c.config.newError(CodeOpenLocalFile, err)
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return "", c.config.newError(CodeOpenLocalFile, err)
+	}
+
+	fileName := filepath.Base(localPath)
+	progress := c.config.newTransferProgress(fileName, info.Size(), onProgress)
+
+	// 小文件或使用外部 Drive Service 時單次上傳（重試時從文件開頭重新讀取）
+	if c.httpClient == nil || info.Size() <= c.config.uploadChunkSize() {
+		if fileID == "" {
+			meta := &drive.File{Name: fileName, Parents: []string{c.folderID}}
+			return c.createFromReader(ctx, meta, file, progress)
+		}
+		return c.updateFromReader(ctx, fileID, nil, file, progress)
+	}
+
+	op := CodeUploadFailed
+	if fileID != "" {
+		op = CodeUpdateFailed
+	}
+
+	target := &journalEntry{
+		Name:    fileName,
+		Size:    info.Size(),
+		ModTime: info.ModTime(),
+		FileID:  fileID,
+	}
+	if fileID == "" {
+		target.FolderID = c.folderID
+	}
+
+	// 內容校驗和不一致時重新上傳（已創建的文件改為覆蓋），其他錯誤已在分塊上傳內部重試
+	// 校驗和在發送分塊時計算，校驗通過後保存 SHA-256
+	var sum *uploadChecksum
+	err = c.retryIf(ctx, op, isChecksumMismatch, func() error {
+		sum = newUploadChecksum()
+		uploaded, err := c.resumableUpload(ctx, op, localPath, file, target, sum, progress)
+		if err != nil {
+			return err
+		}
+		target.FileID, target.FolderID = uploaded.Id, ""
+		// 補算未經本次上傳發送的內容（如會話已在上次運行中完成）
+		if err := sum.seek(file, target.Size); err != nil {
+			return c.config.newError(CodeOpenLocalFile, err)
+		}
+		return c.verifyUpload(fileName, uploaded, sum)
+	})
+	if err != nil {
+		// 文件已創建後失敗時同時返回文件 ID
+		return target.FileID, c.config.newError(op, err)
+	}
+	progress.finish()
+
+	if err := c.saveSHA256(ctx, target.FileID, sum); err != nil {
+		return target.FileID, err
+	}
+	return target.FileID, nil
+}
+
+// resumableUpload 使用可續傳上傳會話分塊上傳文件
+// sum 計算發送內容的校驗和
+func (c *Client) resumableUpload(ctx context.Context, op ErrorCode, localPath string, file *os.File, target *journalEntry, sum *uploadChecksum, progress *transferProgress) (*drive.File, error) {
+	key, err := filepath.Abs(localPath)
+	if err != nil {
+		key = localPath
+	}
+
+	// 查找可續傳的會話
+	entry, err := c.journal.load(key)
+	if err != nil {
+		c.config.logger().Warningf(c.config.text(msgJournalFailed), err)
+	}
+	if entry != nil && !entry.matches(target, time.Now()) {
+		entry = nil
+	}
+
+	// 會話失效時最多重新開始一次
+	for restarted := false; ; restarted = true {
+		offset := int64(0)
+		if entry != nil {
+			// 以服務端記錄的偏移為準
+			var done *drive.File
+			err := c.retry(ctx, op, func() (err error) {
+				offset, done, err = c.queryUpload(ctx, entry.SessionURI, target.Size)
+				return err
+			})
+			if done != nil {
+				c.removeJournal(key)
+				return done, nil
+			}
+			if err != nil && !isSessionExpired(err) {
+				return nil, err
+			}
+			if err == nil {
+				progress.resume(offset)
+				c.config.logger().Infof(c.config.text(msgUploadResumed), localPath, formatBytes(offset), formatBytes(target.Size))
+			} else {
+				c.config.logger().Warningf(c.config.text(msgUploadExpired), localPath)
+				c.removeJournal(key)
+				entry = nil
+			}
+		}
+
+		if entry == nil {
+			sessionURI, err := c.startUpload(ctx, op, target)
+			if err != nil {
+				return nil, err
+			}
+			entry = &journalEntry{}
+			*entry = *target
+			entry.SessionURI = sessionURI
+			entry.StartedAt = time.Now()
+			c.saveJournal(key, entry)
+		}
+
+		uploaded, err := c.uploadChunks(ctx, op, key, file, entry, offset, sum, progress)
+		if err == nil {
+			c.removeJournal(key)
+			return uploaded, nil
+		}
+		if !isSessionExpired(err) || restarted {
+			// 保留日志記錄，下次從斷點繼續
+			return nil, err
+		}
+
+		c.config.logger().Warningf(c.config.text(msgUploadExpired), localPath)
+		c.removeJournal(key)
+		entry = nil
+	}
+}
+
+// uploadChunks 從 offset 開始按分塊發送文件內容，每個分塊完成後記錄進度
+// 發送時計算分塊內容的校驗和，offset 之前未經本次發送的內容從本地文件補算
+func (c *Client) uploadChunks(ctx context.Context, op ErrorCode, key string, file *os.File, entry *journalEntry, offset int64, sum *uploadChecksum, progress *transferProgress) (*drive.File, error) {
+	chunkSize := c.config.uploadChunkSize()
+
+	for {
+		var done *drive.File
+		resync := false
+		err := c.retry(ctx, op, func() (err error) {
+			// 上次發送失敗時服務端可能已接收部分內容，先同步偏移
+			if resync {
+				if offset, done, err = c.queryUpload(ctx, entry.SessionURI, entry.Size); err != nil || done != nil {
+					return err
+				}
+			}
+			if err := sum.seek(file, offset); err != nil {
+				return c.config.newError(CodeOpenLocalFile, err)
+			}
+			n := min(chunkSize, entry.Size-offset)
+			progress.set(offset)
+			chunk := sum.reader(progress.reader(io.NewSectionReader(file, offset, n)))
+			var next int64
+			next, done, err = c.sendChunk(ctx, entry.SessionURI, chunk, offset, n, entry.Size)
+			if err != nil {
+				resync = true
+				return err
+			}
+			offset = next
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		if done != nil {
+			return done, nil
+		}
+
+		entry.Offset = offset
+		c.saveJournal(key, entry)
+	}
+}
+
+// startUpload 創建可續傳上傳會話，返回會話地址
+func (c *Client) startUpload(ctx context.Context, op ErrorCode, target *journalEntry) (string, error) {
+	method, path := http.MethodPost, "/upload/drive/v3/files"
+	meta := &drive.File{Name: target.Name}
+	if target.FileID != "" {
+		// 清除舊內容的 SHA-256，校驗通過後再保存新值
+		method, path = http.MethodPatch, path+"/"+url.PathEscape(target.FileID)
+		meta = withSHA256(nil, "")
+	} else {
+		meta.Parents = []string{target.FolderID}
+	}
+
+	body, err := json.Marshal(meta)
+	if err != nil {
+		return "", err
+	}
+
+	params := url.Values{}
+	params.Set("uploadType", "resumable")
+	params.Set("supportsAllDrives", "true")
+	params.Set("fields", uploadedFileFields)
+	uploadURL := googleapi.ResolveRelative(c.service.BasePath, path) + "?" + params.Encode()
+
+	var location string
+	err = c.retry(ctx, op, func() error {
+		req, err := http.NewRequestWithContext(ctx, method, uploadURL, bytes.NewReader(body))
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Content-Type", "application/json; charset=UTF-8")
+		req.Header.Set("X-Upload-Content-Length", strconv.FormatInt(target.Size, 10))
+
+		resp, err := c.doUploadRequest(req)
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+
+		if err := googleapi.CheckResponse(resp); err != nil {
+			return err
+		}
+		location = resp.Header.Get("Location")
+		if location == "" {
+			return c.config.newError(CodeMissingUploadLocation, nil)
+		}
+		return nil
+	})
+	return location, err
+}
+
+// queryUpload 查詢可續傳上傳會話的狀態
+// 返回: 服務端已接收的字節數；上傳已完成時返回文件信息
+func (c *Client) queryUpload(ctx context.Context, sessionURI string, size int64) (int64, *drive.File, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, sessionURI, http.NoBody)
+	if err != nil {
+		return 0, nil, err
+	}
+	req.Header.Set("Content-Range", fmt.Sprintf("bytes */%d", size))
+	return c.handleUploadResponse(req)
+}
+
+// sendChunk 發送一個分塊
+// 返回: 服務端已接收的字節數；上傳已完成時返回文件信息
+func (c *Client) sendChunk(ctx context.Context, sessionURI string, chunk io.Reader, offset, n, size int64) (int64, *drive.File, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, sessionURI, chunk)
+	if err != nil {
+		return 0, nil, err
+	}
+	req.ContentLength = n
+	if n > 0 {
+		req.Header.Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", offset, offset+n-1, size))
+	} else {
+		req.Header.Set("Content-Range", fmt.Sprintf("bytes */%d", size))
+	}
+	return c.handleUploadResponse(req)
+}
+
+// handleUploadResponse 發送上傳請求並解析響應
+// 308 表示上傳未完成（Range 頭為已接收的範圍），200/201 表示上傳完成
+func (c *Client) handleUploadResponse(req *http.Request) (int64, *drive.File, error) {
+	resp, err := c.doUploadRequest(req)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusPermanentRedirect {
+		return parseUploadRange(resp.Header.Get("Range")), nil, nil
+	}
+	if err := googleapi.CheckResponse(resp); err != nil {
+		return 0, nil, err
+	}
+
+	var uploaded drive.File
+	if err := json.NewDecoder(resp.Body).Decode(&uploaded); err != nil {
+		return 0, nil, err
+	}
+	return 0, &uploaded, nil
+}
+
+// doUploadRequest 使用已授權的 HTTP 客戶端發送上傳請求
+func (c *Client) doUploadRequest(req *http.Request) (*http.Response, error) {
+	if c.service.UserAgent != "" {
+		req.Header.Set("User-Agent", c.service.UserAgent)
+	}
+	return c.httpClient.Do(req)
+}
+
+// parseUploadRange 解析 308 響應的 Range 頭（如 "bytes=0-1048575"），返回已接收的字節數
+func parseUploadRange(header string) int64 {
+	_, end, ok := strings.Cut(strings.TrimPrefix(header, "bytes="), "-")
+	if !ok {
+		return 0
+	}
+	last, err := strconv.ParseInt(end, 10, 64)
+	if err != nil {
+		return 0
+	}
+	return last + 1
+}
+
+// isSessionExpired 判斷錯誤是否表示上傳會話已失效（404 或 410）
+func isSessionExpired(err error) bool {
+	var apiErr *googleapi.Error
+	return errors.As(err, &apiErr) && (apiErr.Code == http.StatusNotFound || apiErr.Code == http.StatusGone)
+}
+
+// saveJournal 保存上傳進度，失敗時僅記錄日志（不影響本次上傳）
+func (c *Client) saveJournal(key string, entry *journalEntry) {
+	if err := c.journal.save(key, entry); err != nil {
+		c.config.logger().Warningf(c.config.text(msgJournalFailed), err)
+	}
+}
+
+// removeJournal 刪除上傳記錄，失敗時僅記錄日志
+func (c *Client) removeJournal(key string) {
+	if err := c.journal.remove(key); err != nil {
+		c.config.logger().Warningf(c.config.text(msgJournalFailed), err)
+	}
+}
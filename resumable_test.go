@@ -0,0 +1,162 @@
+package gdrive_test
+
+import (
+	"bytes"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"syscall"
+	"testing"
+
+	"github.com/Digman/gdrive"
+	"github.com/Digman/gdrive/gdrivetest"
+)
+
+// chunkTransport 記錄分塊上傳請求的 Content-Range，fail 返回 true 的請求不發送並返回連接錯誤
+type chunkTransport struct {
+	base http.RoundTripper
+	fail func(r *http.Request) bool
+
+	mu     sync.Mutex
+	ranges []string
+}
+
+func (t *chunkTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	if r.Method == http.MethodPut {
+		t.mu.Lock()
+		t.ranges = append(t.ranges, r.Header.Get("Content-Range"))
+		t.mu.Unlock()
+	}
+	if t.fail != nil && t.fail(r) {
+		return nil, syscall.ECONNREFUSED
+	}
+	return t.base.RoundTrip(r)
+}
+
+// withJournal 使用 256 KiB 分塊並記錄續傳日志
+func withJournal(journal string) func(*gdrive.Config) {
+	return func(c *gdrive.Config) {
+		c.Retry = fastRetry(2)
+		c.UploadChunkSize = 256 << 10
+		c.UploadJournalFile = journal
+	}
+}
+
+func TestResumableUploadResumesFromJournal(t *testing.T) {
+	srv := gdrivetest.NewServer()
+	defer srv.Close()
+
+	journal := filepath.Join(t.TempDir(), "journal.json")
+	content := bytes.Repeat([]byte("0123456789abcdef"), 700<<10/16)
+	localPath := writeTempFile(t, "big.bin", content)
+
+	// 第一個分塊上傳後網絡中斷
+	interrupted := &chunkTransport{
+		base: srv.HTTPClient().Transport,
+		fail: func(r *http.Request) bool {
+			return r.Method == http.MethodPut && !strings.HasPrefix(r.Header.Get("Content-Range"), "bytes 0-")
+		},
+	}
+	client := newTestClient(t, srv, interrupted, withJournal(journal))
+	if _, err := client.UploadFile(localPath); err == nil {
+		t.Fatal("UploadFile should fail")
+	}
+	if _, err := os.Stat(journal); err != nil {
+		t.Fatalf("journal not kept: %v", err)
+	}
+	if n := len(srv.FindByName("big.bin")); n != 0 {
+		t.Fatalf("files = %d, want 0", n)
+	}
+
+	// 重啓後從日志記錄的會話繼續，不重新發送第一個分塊
+	resumed := &chunkTransport{base: srv.HTTPClient().Transport}
+	client = newTestClient(t, srv, resumed, withJournal(journal))
+	fileID, err := client.UploadFile(localPath)
+	if err != nil {
+		t.Fatalf("UploadFile: %v", err)
+	}
+	for _, r := range resumed.ranges {
+		if strings.HasPrefix(r, "bytes 0-") {
+			t.Fatalf("chunk ranges = %v, first chunk sent again", resumed.ranges)
+		}
+	}
+	if len(resumed.ranges) == 0 || !strings.HasPrefix(resumed.ranges[0], "bytes */") {
+		t.Fatalf("chunk ranges = %v, want status query first", resumed.ranges)
+	}
+
+	// 續傳前已上傳的部分也計入 SHA-256
+	assertUploaded(t, srv, "big.bin", fileID, content)
+	if _, err := os.Stat(journal); err == nil {
+		// 日志文件保留時不應再有該文件的記錄
+		data, _ := os.ReadFile(journal)
+		if bytes.Contains(data, []byte("big.bin")) {
+			t.Fatalf("journal still has entry: %s", data)
+		}
+	}
+}
+
+func TestResumableUploadIgnoresChangedFile(t *testing.T) {
+	srv := gdrivetest.NewServer()
+	defer srv.Close()
+
+	journal := filepath.Join(t.TempDir(), "journal.json")
+	localPath := writeTempFile(t, "big.bin", bytes.Repeat([]byte("a"), 600<<10))
+
+	interrupted := &chunkTransport{
+		base: srv.HTTPClient().Transport,
+		fail: func(r *http.Request) bool {
+			return r.Method == http.MethodPut && !strings.HasPrefix(r.Header.Get("Content-Range"), "bytes 0-")
+		},
+	}
+	client := newTestClient(t, srv, interrupted, withJournal(journal))
+	if _, err := client.UploadFile(localPath); err == nil {
+		t.Fatal("UploadFile should fail")
+	}
+
+	// 本地文件已改變時不能續傳舊會話，重新開始上傳
+	content := bytes.Repeat([]byte("b"), 700<<10)
+	if err := os.WriteFile(localPath, content, 0644); err != nil {
+		t.Fatal(err)
+	}
+	resumed := &chunkTransport{base: srv.HTTPClient().Transport}
+	client = newTestClient(t, srv, resumed, withJournal(journal))
+	if _, err := client.UploadFile(localPath); err != nil {
+		t.Fatalf("UploadFile: %v", err)
+	}
+	if len(resumed.ranges) == 0 || !strings.HasPrefix(resumed.ranges[0], "bytes 0-") {
+		t.Fatalf("chunk ranges = %v, want upload from start", resumed.ranges)
+	}
+	files := srv.FindByName("big.bin")
+	if len(files) != 1 || !bytes.Equal(files[0].Content, content) {
+		t.Fatalf("files = %d, want one file with new content", len(files))
+	}
+}
+
+// dropLocation 移除創建可續傳上傳會話響應中的 Location 頭
+type dropLocation struct {
+	base http.RoundTripper
+}
+
+func (t dropLocation) RoundTrip(r *http.Request) (*http.Response, error) {
+	resp, err := t.base.RoundTrip(r)
+	if err == nil && r.URL.Query().Get("uploadType") == "resumable" {
+		resp.Header.Del("Location")
+	}
+	return resp, err
+}
+
+func TestResumableUploadMissingLocation(t *testing.T) {
+	srv := gdrivetest.NewServer()
+	defer srv.Close()
+	client := newTestClient(t, srv, dropLocation{srv.HTTPClient().Transport}, withSmallChunks)
+
+	_, err := client.UploadFile(writeTempFile(t, "big.bin", bytes.Repeat([]byte("a"), 300<<10)))
+	if !hasErrorCode(err, gdrive.CodeMissingUploadLocation) {
+		t.Fatalf("UploadFile = %v, want %s", err, gdrive.CodeMissingUploadLocation)
+	}
+	if n := len(srv.FindByName("big.bin")); n != 0 {
+		t.Fatalf("files = %d, want 0", n)
+	}
+}
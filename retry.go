@@ -0,0 +1,241 @@
+package gdrive
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/rand"
+	"net"
+	"net/http"
+	"strconv"
+	"syscall"
+	"time"
+
+	"google.golang.org/api/googleapi"
+)
+
+// 默認重試策略參數
+const (
+	defaultRetryMaxAttempts = 5
+	defaultRetryBaseDelay   = time.Second
+	defaultRetryMaxDelay    = 32 * time.Second
+	defaultRetryJitter      = 0.2
+)
+
+// RetryPolicy Drive API 請求的重試策略（指數退避）
+// 第 n 次重試前等待 BaseDelay * 2^(n-1)，不超過 MaxDelay，並疊加 ±Jitter 比例的隨機抖動
+// 服務端返回 Retry-After 時以其為準（同樣不超過 MaxDelay）
+type RetryPolicy struct {
+	MaxAttempts int           // 最大嘗試次數（包括首次請求），0 使用默認值 5，1 表示不重試
+	BaseDelay   time.Duration // 首次重試前的等待時間，0 使用默認值 1s
+	MaxDelay    time.Duration // 單次等待時間上限（包括 Retry-After），0 使用默認值 32s
+	Jitter      float64       // 隨機抖動比例（0~1），0 表示不抖動
+
+	// Retryable 判斷錯誤是否可重試（可選，nil 使用 IsRetryableError）
+	Retryable func(err error) bool
+}
+
+// DefaultRetryPolicy 返回默認重試策略（最多 5 次，1s 起指數退避，上限 32s，20% 抖動）
+func DefaultRetryPolicy() *RetryPolicy {
+	return &RetryPolicy{
+		MaxAttempts: defaultRetryMaxAttempts,
+		BaseDelay:   defaultRetryBaseDelay,
+		MaxDelay:    defaultRetryMaxDelay,
+		Jitter:      defaultRetryJitter,
+	}
+}
+
+// retryPolicy 返回配置中的重試策略（未設置時使用默認策略）
+func (c *Config) retryPolicy() *RetryPolicy {
+	if c.Retry != nil {
+		return c.Retry
+	}
+	return DefaultRetryPolicy()
+}
+
+// maxAttempts 返回最大嘗試次數
+func (p *RetryPolicy) maxAttempts() int {
+	if p.MaxAttempts <= 0 {
+		return defaultRetryMaxAttempts
+	}
+	return p.MaxAttempts
+}
+
+// retryable 判斷錯誤是否可重試
+func (p *RetryPolicy) retryable(err error) bool {
+	if p.Retryable != nil {
+		return p.Retryable(err)
+	}
+	return IsRetryableError(err)
+}
+
+// delay 計算第 attempt 次重試前的等待時間（attempt 從 1 開始）
+func (p *RetryPolicy) delay(attempt int, err error) time.Duration {
+	maxDelay := p.MaxDelay
+	if maxDelay <= 0 {
+		maxDelay = defaultRetryMaxDelay
+	}
+
+	// 服務端指定了 Retry-After 時以其為準，但不超過 MaxDelay，避免異常的響應頭使請求長時間掛起
+	if retryAfter := retryAfterDelay(err); retryAfter > 0 {
+		return min(retryAfter, maxDelay)
+	}
+
+	base := p.BaseDelay
+	if base <= 0 {
+		base = defaultRetryBaseDelay
+	}
+
+	d := base
+	for i := 1; i < attempt && d < maxDelay; i++ {
+		d *= 2
+	}
+	if d > maxDelay {
+		d = maxDelay
+	}
+
+	if p.Jitter > 0 {
+		jitter := p.Jitter
+		if jitter > 1 {
+			jitter = 1
+		}
+		// 在 [d*(1-jitter), d*(1+jitter)] 範圍內隨機
+		d = time.Duration(float64(d) * (1 + jitter*(2*rand.Float64()-1)))
+	}
+	return d
+}
+
+// IsRetryableError 判斷錯誤是否為可重試的臨時錯誤
+// 包括：429、5xx、403 userRateLimitExceeded / rateLimitExceeded、網絡超時、連接中斷和上傳內容校驗和不一致
+// context 取消、授權失效等錯誤不可重試
+func IsRetryableError(err error) bool {
+	if err == nil {
+		return false
+	}
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+
+	// 傳輸過程中內容損壞，重新上傳
+	if isChecksumMismatch(err) {
+		return true
+	}
+
+	var apiErr *googleapi.Error
+	if errors.As(err, &apiErr) {
+		switch {
+		case apiErr.Code == http.StatusTooManyRequests:
+			return true
+		case apiErr.Code >= 500:
+			return true
+		default:
+			return isRateLimited(apiErr)
+		}
+	}
+
+	// 連接中斷
+	if errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, syscall.EPIPE) {
+		return true
+	}
+
+	// 網絡超時
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
+
+// isRejectedError 判斷錯誤是否表示請求未被服務端處理（限流或無法建立連接）
+// 5xx、超時和連接中斷時服務端可能已處理完請求，只是響應丟失
+func isRejectedError(err error) bool {
+	var apiErr *googleapi.Error
+	if errors.As(err, &apiErr) {
+		return apiErr.Code == http.StatusTooManyRequests || isRateLimited(apiErr)
+	}
+	return errors.Is(err, syscall.ECONNREFUSED)
+}
+
+// isRateLimited 判斷是否為 403 限流錯誤（userRateLimitExceeded / rateLimitExceeded）
+func isRateLimited(apiErr *googleapi.Error) bool {
+	if apiErr.Code != http.StatusForbidden {
+		return false
+	}
+	for _, item := range apiErr.Errors {
+		if item.Reason == "userRateLimitExceeded" || item.Reason == "rateLimitExceeded" {
+			return true
+		}
+	}
+	return false
+}
+
+// retryAfterDelay 解析錯誤響應中的 Retry-After 頭（秒）
+func retryAfterDelay(err error) time.Duration {
+	var apiErr *googleapi.Error
+	if !errors.As(err, &apiErr) || apiErr.Header == nil {
+		return 0
+	}
+	seconds, parseErr := strconv.Atoi(apiErr.Header.Get("Retry-After"))
+	if parseErr != nil || seconds <= 0 {
+		return 0
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// retry 按配置的重試策略執行 Drive API 請求，最終失敗時返回 *APIError
+// op: 操作失敗時的錯誤代碼（用於日志）
+// fn: 每次嘗試執行的請求，需要自行重置請求體（如將文件重新定位到開頭）
+func (c *Client) retry(ctx context.Context, op ErrorCode, fn func() error) error {
+	return c.retryIf(ctx, op, c.config.retryPolicy().retryable, fn)
+}
+
+// retryIf 與 retry 相同，但只重試 retryable 返回 true 的錯誤
+// 用於 fn 內部已按策略重試過請求、外層只需重試特定錯誤的場景
+func (c *Client) retryIf(ctx context.Context, op ErrorCode, retryable func(error) bool, fn func() error) error {
+	policy := c.config.retryPolicy()
+	maxAttempts := policy.maxAttempts()
+
+	for attempt := 1; ; attempt++ {
+		err := fn()
+		if err == nil {
+			return nil
+		}
+		if attempt >= maxAttempts || ctx.Err() != nil || !retryable(err) {
+			return wrapAPIError(err, c.config.language())
+		}
+
+		delay := policy.delay(attempt, err)
+		c.config.logger().Warningf(c.config.text(msgRetry), c.config.text(messageKey(op)), delay, attempt, maxAttempts-1, err)
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return wrapAPIError(err, c.config.language())
+		}
+	}
+}
+
+// retryCreate 按重試策略執行創建請求，避免重試產生重複文件
+// 請求未被服務端處理（isRejectedError）時直接重試；其他可重試錯誤下文件可能已創建成功，
+// lookup 為 nil 時不再重試，否則每次重試前先調用 lookup 查找，找到已創建的文件（返回 true）時視為成功
+func (c *Client) retryCreate(ctx context.Context, op ErrorCode, lookup func() (bool, error), create func() error) error {
+	policy := c.config.retryPolicy()
+	retryable := func(err error) bool {
+		return policy.retryable(err) && (lookup != nil || isRejectedError(err))
+	}
+
+	uncertain := false
+	return c.retryIf(ctx, op, retryable, func() error {
+		if uncertain {
+			found, err := lookup()
+			if err != nil || found {
+				return err
+			}
+		}
+		err := create()
+		uncertain = err != nil && !isRejectedError(err)
+		return err
+	})
+}
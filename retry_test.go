@@ -0,0 +1,299 @@
+package gdrive_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/Digman/gdrive"
+	"github.com/Digman/gdrive/gdrivetest"
+	"google.golang.org/api/googleapi"
+)
+
+// countRequests 統計指定方法和路徑前綴的請求數
+func countRequests(srv *gdrivetest.Server, method, path string) int {
+	n := 0
+	for _, r := range srv.Requests() {
+		if r.Method == method && strings.HasPrefix(r.Path, path) {
+			n++
+		}
+	}
+	return n
+}
+
+func TestIsRetryableError(t *testing.T) {
+	apiErr := func(code int, reason string) error {
+		return &googleapi.Error{Code: code, Errors: []googleapi.ErrorItem{{Reason: reason}}}
+	}
+
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"nil", nil, false},
+		{"429", apiErr(http.StatusTooManyRequests, "rateLimitExceeded"), true},
+		{"403 userRateLimitExceeded", apiErr(http.StatusForbidden, "userRateLimitExceeded"), true},
+		{"403 rateLimitExceeded", apiErr(http.StatusForbidden, "rateLimitExceeded"), true},
+		{"403 insufficientPermissions", apiErr(http.StatusForbidden, "insufficientPermissions"), false},
+		{"403 storageQuotaExceeded", apiErr(http.StatusForbidden, "storageQuotaExceeded"), false},
+		{"500", apiErr(http.StatusInternalServerError, "internalError"), true},
+		{"503", apiErr(http.StatusServiceUnavailable, "backendError"), true},
+		{"404", apiErr(http.StatusNotFound, "notFound"), false},
+		{"400", apiErr(http.StatusBadRequest, "invalid"), false},
+		{"wrapped 503", fmt.Errorf("list: %w", apiErr(http.StatusServiceUnavailable, "")), true},
+		{"connection reset", fmt.Errorf("read: %w", syscall.ECONNRESET), true},
+		{"checksum mismatch", gdrive.ErrChecksumMismatch, true},
+		{"context canceled", context.Canceled, false},
+		{"deadline exceeded", fmt.Errorf("get: %w", context.DeadlineExceeded), false},
+		{"other", errors.New("boom"), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := gdrive.IsRetryableError(tt.err); got != tt.want {
+				t.Fatalf("IsRetryableError(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRetryTransientErrors(t *testing.T) {
+	faults := []gdrivetest.Fault{
+		gdrivetest.RateLimitExceeded,
+		gdrivetest.TooManyRequests,
+		gdrivetest.InternalError,
+		gdrivetest.ServiceUnavailable,
+	}
+
+	for _, fault := range faults {
+		t.Run(fmt.Sprint(fault.Status), func(t *testing.T) {
+			srv := gdrivetest.NewServer()
+			defer srv.Close()
+			client := newTestClient(t, srv, nil, nil)
+
+			srv.FailNext(2, fault)
+			if _, err := client.FindFiles(gdrive.NewQuery().Name("a")); err != nil {
+				t.Fatalf("FindFiles: %v", err)
+			}
+			if n := countRequests(srv, http.MethodGet, "/drive/v3/files"); n != 3 {
+				t.Fatalf("requests = %d, want 3", n)
+			}
+		})
+	}
+}
+
+func TestRetryGivesUp(t *testing.T) {
+	srv := gdrivetest.NewServer()
+	defer srv.Close()
+	client := newTestClient(t, srv, nil, nil)
+
+	srv.InjectFault(gdrivetest.TooManyRequests)
+	_, err := client.FindFiles(gdrive.NewQuery().Name("a"))
+	if !errors.Is(err, gdrive.ErrRateLimited) {
+		t.Fatalf("FindFiles = %v, want ErrRateLimited", err)
+	}
+	var apiErr *gdrive.APIError
+	if !errors.As(err, &apiErr) || apiErr.Code != http.StatusTooManyRequests {
+		t.Fatalf("FindFiles = %v, want *APIError 429", err)
+	}
+	if n := countRequests(srv, http.MethodGet, "/drive/v3/files"); n != 3 {
+		t.Fatalf("requests = %d, want 3", n)
+	}
+}
+
+func TestRetrySkipsPermanentErrors(t *testing.T) {
+	srv := gdrivetest.NewServer()
+	defer srv.Close()
+	client := newTestClient(t, srv, nil, nil)
+
+	srv.InjectFault(gdrivetest.Fault{Status: http.StatusBadRequest, Reason: "invalid"})
+	if _, err := client.FindFiles(gdrive.NewQuery().Name("a")); err == nil {
+		t.Fatal("FindFiles should fail")
+	}
+	if n := countRequests(srv, http.MethodGet, "/drive/v3/files"); n != 1 {
+		t.Fatalf("requests = %d, want 1", n)
+	}
+}
+
+func TestRetryAfterIsCappedByMaxDelay(t *testing.T) {
+	srv := gdrivetest.NewServer()
+	defer srv.Close()
+	client := newTestClient(t, srv, nil, func(c *gdrive.Config) { c.Retry = fastRetry(2) })
+
+	// Retry-After 超過 MaxDelay（10ms）時按 MaxDelay 等待
+	fault := gdrivetest.TooManyRequests
+	fault.RetryAfter = 3600
+	srv.FailNext(1, fault)
+
+	start := time.Now()
+	if _, err := client.FindFiles(gdrive.NewQuery().Name("a")); err != nil {
+		t.Fatalf("FindFiles: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Fatalf("FindFiles took %s, Retry-After not capped", elapsed)
+	}
+}
+
+func TestCreateFolderNotRetriedAfterServerError(t *testing.T) {
+	srv := gdrivetest.NewServer()
+	defer srv.Close()
+	client := newTestClient(t, srv, nil, nil)
+
+	// 服務端已創建文件夾但返回 503，重試會產生重複文件夾
+	srv.InjectFault(gdrivetest.Fault{
+		Method:        http.MethodPost,
+		Path:          "/drive/v3/files",
+		Status:        http.StatusServiceUnavailable,
+		AfterHandling: true,
+		Times:         1,
+	})
+	if _, err := client.CreateFolder("reports", ""); err == nil {
+		t.Fatal("CreateFolder should fail")
+	}
+	if n := len(srv.FindByName("reports")); n != 1 {
+		t.Fatalf("folders = %d, want 1", n)
+	}
+
+	// 限流表示請求未被處理，可以重試
+	srv.FailNext(1, gdrivetest.RateLimitExceeded)
+	if _, err := client.CreateFolder("logs", ""); err != nil {
+		t.Fatalf("CreateFolder after rate limit: %v", err)
+	}
+	if n := len(srv.FindByName("logs")); n != 1 {
+		t.Fatalf("folders = %d, want 1", n)
+	}
+}
+
+func TestMkdirAllLooksUpBeforeRetry(t *testing.T) {
+	srv := gdrivetest.NewServer()
+	defer srv.Close()
+	client := newTestClient(t, srv, nil, nil)
+
+	srv.InjectFault(gdrivetest.Fault{
+		Method:        http.MethodPost,
+		Path:          "/drive/v3/files",
+		Status:        http.StatusInternalServerError,
+		AfterHandling: true,
+		Times:         1,
+	})
+	folderID, err := client.MkdirAll("archive/2024")
+	if err != nil {
+		t.Fatalf("MkdirAll: %v", err)
+	}
+
+	for _, name := range []string{"archive", "2024"} {
+		if n := len(srv.FindByName(name)); n != 1 {
+			t.Fatalf("folders named %s = %d, want 1", name, n)
+		}
+	}
+	if f := srv.FindByName("2024")[0]; f.ID != folderID {
+		t.Fatalf("MkdirAll = %s, want %s", folderID, f.ID)
+	}
+}
+
+func TestUploadCreateNotRetriedAfterServerError(t *testing.T) {
+	srv := gdrivetest.NewServer()
+	defer srv.Close()
+	client := newTestClient(t, srv, nil, nil)
+
+	srv.InjectFault(gdrivetest.Fault{
+		Method:        http.MethodPost,
+		Path:          "/upload/drive/v3/files",
+		Status:        http.StatusBadGateway,
+		AfterHandling: true,
+		Times:         1,
+	})
+	_, err := client.UploadReader(context.Background(), "a.txt", bytes.NewReader([]byte("hello")), nil)
+	if err == nil {
+		t.Fatal("UploadReader should fail")
+	}
+	if n := len(srv.FindByName("a.txt")); n != 1 {
+		t.Fatalf("files = %d, want 1", n)
+	}
+
+	// 請求未被處理的錯誤照常重試
+	srv.FailNext(2, gdrivetest.TooManyRequests)
+	if _, err := client.UploadReader(context.Background(), "b.txt", bytes.NewReader([]byte("hello")), nil); err != nil {
+		t.Fatalf("UploadReader after 429: %v", err)
+	}
+	if n := len(srv.FindByName("b.txt")); n != 1 {
+		t.Fatalf("files = %d, want 1", n)
+	}
+}
+
+func TestUploadOrUpdateNotCreatedAfterLookupFailure(t *testing.T) {
+	srv := gdrivetest.NewServer()
+	defer srv.Close()
+	client := newTestClient(t, srv, nil, nil)
+	srv.AddFile(gdrivetest.File{Name: "a.txt", Parents: []string{client.GetFolderID()}, Content: []byte("old")})
+
+	// 查找同名文件一直失敗（超出重試次數）時不能確定文件是否存在，不應創建新文件
+	srv.InjectFault(gdrivetest.Fault{Method: http.MethodGet, Path: "/drive/v3/files", Status: http.StatusInternalServerError})
+	_, _, err := client.UploadOrUpdateFileContext(context.Background(), writeTempFile(t, "a.txt", []byte("new")))
+	if gdrive.ErrorCodeOf(err) != gdrive.CodeFindFileFailed {
+		t.Fatalf("UploadOrUpdateFileContext = %v, want %s", err, gdrive.CodeFindFileFailed)
+	}
+	if n := countRequests(srv, http.MethodGet, "/drive/v3/files"); n != 3 {
+		t.Fatalf("lookup requests = %d, want 3", n)
+	}
+	if n := countRequests(srv, http.MethodPost, "/upload/"); n != 0 {
+		t.Fatalf("upload requests = %d, want 0", n)
+	}
+	if n := len(srv.FindByName("a.txt")); n != 1 {
+		t.Fatalf("files named a.txt = %d, want 1", n)
+	}
+}
+
+// lossyTransport 正常發送請求，但丟棄 lose 返回 true 的請求的響應（模擬服務端已處理而連接中斷）
+type lossyTransport struct {
+	base http.RoundTripper
+	lose func(r *http.Request) bool
+}
+
+func (t *lossyTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	resp, err := t.base.RoundTrip(r)
+	if err != nil || !t.lose(r) {
+		return resp, err
+	}
+	resp.Body.Close()
+	return nil, io.ErrUnexpectedEOF
+}
+
+func TestResumableUploadFinalChunkLost(t *testing.T) {
+	srv := gdrivetest.NewServer()
+	defer srv.Close()
+
+	// 第二個（最後一個）分塊已上傳完成但響應丟失
+	puts := 0
+	transport := &lossyTransport{
+		base: srv.HTTPClient().Transport,
+		lose: func(r *http.Request) bool {
+			if r.Method != http.MethodPut {
+				return false
+			}
+			puts++
+			return puts == 2
+		},
+	}
+	client := newTestClient(t, srv, transport, withSmallChunks)
+
+	localPath := writeTempFile(t, "big.bin", bytes.Repeat([]byte("x"), 300<<10))
+	fileID, err := client.UploadFile(localPath)
+	if err != nil {
+		t.Fatalf("UploadFile: %v", err)
+	}
+
+	// 重試時從會話狀態取得上傳結果，不會重新創建文件
+	files := srv.FindByName("big.bin")
+	if len(files) != 1 || files[0].ID != fileID || len(files[0].Content) != 300<<10 {
+		t.Fatalf("files = %d, want one file %s", len(files), fileID)
+	}
+}
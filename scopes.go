@@ -0,0 +1,149 @@
+package gdrive
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+
+	"golang.org/x/oauth2"
+	"google.golang.org/api/drive/v3"
+)
+
+// scopePrefix Google Drive 權限範圍前綴
+const scopePrefix = "https://www.googleapis.com/auth/"
+
+// appDataFolderID 應用數據文件夾的特殊 ID
+const appDataFolderID = "appDataFolder"
+
+// scopes 返回配置請求的權限範圍（完整 URL 形式）
+// 未配置時默認使用 drive.file
+func (c *Config) scopes() []string {
+	if len(c.Scopes) == 0 {
+		return []string{drive.DriveFileScope}
+	}
+
+	scopes := make([]string, 0, len(c.Scopes))
+	for _, scope := range c.Scopes {
+		scopes = append(scopes, normalizeScope(scope))
+	}
+	return scopes
+}
+
+// useAppDataFolder 判斷是否只能訪問應用數據文件夾（僅請求了 drive.appdata 範圍）
+func (c *Config) useAppDataFolder() bool {
+	scopes := c.scopes()
+	for _, scope := range scopes {
+		if scope != drive.DriveAppdataScope {
+			return false
+		}
+	}
+	return true
+}
+
+// normalizeScope 將簡寫（如 "drive.readonly"）轉換為完整的權限範圍 URL
+func normalizeScope(scope string) string {
+	scope = strings.TrimSpace(scope)
+	if strings.Contains(scope, "://") {
+		return scope
+	}
+	return scopePrefix + scope
+}
+
+// tokenScopes 返回 Token 已授予的權限範圍
+// 沒有記錄時返回 nil（舊版本保存的 Token，或自定義 TokenStore 未保存 scope 字段）
+func tokenScopes(token *oauth2.Token) []string {
+	scope, _ := token.Extra("scope").(string)
+	if scope == "" {
+		return nil
+	}
+	return strings.Fields(scope)
+}
+
+// withScopes 返回記錄了權限範圍的 Token 副本
+func withScopes(token *oauth2.Token, scopes []string) *oauth2.Token {
+	return token.WithExtra(map[string]interface{}{"scope": strings.Join(scopes, " ")})
+}
+
+// missingScopes 返回 Token 缺少的權限範圍
+// 未記錄權限範圍的 Token 視為只授予了默認的 drive.file，需要其他範圍時重新授權
+func missingScopes(token *oauth2.Token, required []string) []string {
+	granted := tokenScopes(token)
+	if granted == nil {
+		granted = []string{drive.DriveFileScope}
+	}
+
+	grantedSet := make(map[string]bool, len(granted))
+	for _, scope := range granted {
+		grantedSet[scope] = true
+	}
+
+	var missing []string
+	for _, scope := range required {
+		if grantedSet[scope] {
+			continue
+		}
+		// 完整 drive 範圍包含 drive.file 和 drive.readonly
+		if grantedSet[drive.DriveScope] && (scope == drive.DriveFileScope || scope == drive.DriveReadonlyScope) {
+			continue
+		}
+		missing = append(missing, scope)
+	}
+	return missing
+}
+
+// checkDeviceScopeError 將 Device Flow 拒絕權限範圍的錯誤轉換為明確的錯誤信息
+func checkDeviceScopeError(config *Config, err error, scopes []string) error {
+	var retrieveErr *oauth2.RetrieveError
+	if errors.As(err, &retrieveErr) && retrieveErr.ErrorCode == "invalid_scope" {
+		return config.newError(CodeDeviceScopeUnsupported, err, strings.Join(scopes, ", "))
+	}
+	return err
+}
+
+// isInsufficientScope 判斷響應是否表示 Access Token 缺少權限範圍（403 insufficientPermissions）
+// 需要讀取響應體時會將其恢復，不影響調用方解析
+func isInsufficientScope(resp *http.Response) bool {
+	if resp.StatusCode != http.StatusForbidden {
+		return false
+	}
+	if strings.Contains(resp.Header.Get("WWW-Authenticate"), "insufficient_scope") {
+		return true
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+	resp.Body = struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(data), resp.Body), resp.Body}
+	if err != nil {
+		return false
+	}
+
+	var body struct {
+		Error struct {
+			Errors []struct {
+				Reason string `json:"reason"`
+			} `json:"errors"`
+			Details []struct {
+				Reason string `json:"reason"`
+			} `json:"details"`
+		} `json:"error"`
+	}
+	if json.Unmarshal(data, &body) != nil {
+		return false
+	}
+	for _, item := range body.Error.Errors {
+		if item.Reason == "insufficientPermissions" {
+			return true
+		}
+	}
+	for _, item := range body.Error.Details {
+		if item.Reason == "ACCESS_TOKEN_SCOPE_INSUFFICIENT" {
+			return true
+		}
+	}
+	return false
+}
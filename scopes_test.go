@@ -0,0 +1,149 @@
+package gdrive
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/oauth2"
+	"google.golang.org/api/drive/v3"
+)
+
+func TestMissingScopes(t *testing.T) {
+	tests := []struct {
+		name     string
+		granted  []string // nil 表示未記錄
+		required []string
+		want     []string
+	}{
+		{"unknown treated as drive.file", nil, []string{drive.DriveFileScope}, nil},
+		{"unknown lacks drive", nil, []string{drive.DriveScope}, []string{drive.DriveScope}},
+		{"granted", []string{drive.DriveFileScope}, []string{drive.DriveFileScope}, nil},
+		{"missing", []string{drive.DriveFileScope}, []string{drive.DriveScope}, []string{drive.DriveScope}},
+		{"drive covers file and readonly", []string{drive.DriveScope}, []string{drive.DriveFileScope, drive.DriveReadonlyScope}, nil},
+		{"partially missing", []string{drive.DriveFileScope}, []string{drive.DriveFileScope, drive.DriveAppdataScope}, []string{drive.DriveAppdataScope}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token := &oauth2.Token{AccessToken: "access"}
+			if tt.granted != nil {
+				token = withScopes(token, tt.granted)
+			}
+			got := missingScopes(token, tt.required)
+			if strings.Join(got, " ") != strings.Join(tt.want, " ") {
+				t.Fatalf("missingScopes = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsInsufficientScope(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		header string
+		body   string
+		want   bool
+	}{
+		{"www-authenticate", 403, `Bearer realm="https://accounts.google.com/", error="insufficient_scope"`, "", true},
+		{"legacy reason", 403, "", `{"error":{"code":403,"errors":[{"reason":"insufficientPermissions"}]}}`, true},
+		{"error info", 403, "", `{"error":{"code":403,"details":[{"@type":"type.googleapis.com/google.rpc.ErrorInfo","reason":"ACCESS_TOKEN_SCOPE_INSUFFICIENT"}]}}`, true},
+		{"file permission", 403, "", `{"error":{"code":403,"errors":[{"reason":"insufficientFilePermissions"}]}}`, false},
+		{"rate limit", 403, "", `{"error":{"code":403,"errors":[{"reason":"userRateLimitExceeded"}]}}`, false},
+		{"not forbidden", 401, "", `{"error":{"code":401,"errors":[{"reason":"insufficientPermissions"}]}}`, false},
+		{"not json", 403, "", `forbidden`, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := &http.Response{
+				StatusCode: tt.status,
+				Header:     http.Header{},
+				Body:       io.NopCloser(strings.NewReader(tt.body)),
+			}
+			if tt.header != "" {
+				resp.Header.Set("WWW-Authenticate", tt.header)
+			}
+			if got := isInsufficientScope(resp); got != tt.want {
+				t.Fatalf("isInsufficientScope = %v, want %v", got, tt.want)
+			}
+
+			// 響應體應被恢復
+			body, err := io.ReadAll(resp.Body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(body) != tt.body {
+				t.Fatalf("響應體 = %q, want %q", body, tt.body)
+			}
+		})
+	}
+}
+
+// newInsufficientScopeSession 創建請求總是返回 403 insufficientPermissions 的用戶授權會話
+func newInsufficientScopeSession(t *testing.T, config *Config) *authSession {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusForbidden)
+		io.WriteString(w, `{"error":{"code":403,"errors":[{"reason":"insufficientPermissions"}]}}`)
+	}))
+	t.Cleanup(srv.Close)
+
+	// 模擬自定義 TokenStore 丟失了 scope 字段的 Token，啓動時視為已授予 drive.file
+	token := &oauth2.Token{AccessToken: "access", Expiry: time.Now().Add(time.Hour)}
+	oauthConfig := &oauth2.Config{Scopes: []string{drive.DriveFileScope}}
+	if missing := missingScopes(token, oauthConfig.Scopes); len(missing) > 0 {
+		t.Fatalf("missingScopes = %v, want none", missing)
+	}
+	session := newUserSession(config, oauthConfig, NewMemoryTokenStore(token), token, srv.Client())
+
+	resp, err := session.httpClient.Get(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusForbidden {
+		t.Fatalf("status = %d, want 403", resp.StatusCode)
+	}
+	return session
+}
+
+func TestInsufficientScopeWithoutReauth(t *testing.T) {
+	// 未配置 OnReauth 時只返回 403，會話保持可用
+	session := newInsufficientScopeSession(t, &Config{Logger: testLogger{t}})
+	if state := session.authState(); state != AuthStateAuthenticated {
+		t.Fatalf("authState = %v, want %v", state, AuthStateAuthenticated)
+	}
+	if err := session.waitReady(t.Context()); err != nil {
+		t.Fatalf("waitReady = %v, want nil", err)
+	}
+}
+
+func TestInsufficientScopeStartsReauth(t *testing.T) {
+	var cause error
+	denied := errors.New("denied")
+	config := &Config{
+		Logger: testLogger{t},
+		OnReauth: func(_ context.Context, err error) error {
+			cause = err
+			return denied
+		},
+	}
+
+	// 配置了 OnReauth 時與 invalid_grant 一樣重新授權
+	session := newInsufficientScopeSession(t, config)
+	err := session.waitReady(t.Context())
+	if ErrorCodeOf(err) != CodeUnauthorized || !errors.Is(err, denied) {
+		t.Fatalf("waitReady = %v, want %s caused by OnReauth error", err, CodeUnauthorized)
+	}
+	if ErrorCodeOf(cause) != CodeInsufficientScope {
+		t.Fatalf("OnReauth cause = %v, want %s", cause, CodeInsufficientScope)
+	}
+	if state := session.authState(); state != AuthStateUnauthenticated {
+		t.Fatalf("authState = %v, want %v", state, AuthStateUnauthenticated)
+	}
+}
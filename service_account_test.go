@@ -0,0 +1,176 @@
+package gdrive_test
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/Digman/gdrive"
+	"github.com/Digman/gdrive/gdrivetest"
+)
+
+// fakeJWTServer 模擬服務賬號的 Token 端點：校驗 JWT 斷言的簽名並記錄其聲明
+type fakeJWTServer struct {
+	*httptest.Server
+	key *rsa.PrivateKey
+
+	mu     sync.Mutex
+	header map[string]interface{}   // 最近一次斷言的 JWT 頭
+	claims []map[string]interface{} // 收到的斷言聲明
+}
+
+func newFakeJWTServer(t *testing.T) *fakeJWTServer {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &fakeJWTServer{key: key}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.handleToken))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *fakeJWTServer) handleToken(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil || r.PostForm.Get("grant_type") != "urn:ietf:params:oauth:grant-type:jwt-bearer" {
+		http.Error(w, `{"error":"unsupported_grant_type"}`, http.StatusBadRequest)
+		return
+	}
+
+	parts := strings.Split(r.PostForm.Get("assertion"), ".")
+	if len(parts) != 3 {
+		http.Error(w, `{"error":"invalid_grant"}`, http.StatusBadRequest)
+		return
+	}
+	signature, _ := base64.RawURLEncoding.DecodeString(parts[2])
+	sum := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	if err := rsa.VerifyPKCS1v15(&s.key.PublicKey, crypto.SHA256, sum[:], signature); err != nil {
+		http.Error(w, `{"error":"invalid_grant"}`, http.StatusBadRequest)
+		return
+	}
+
+	var header, claims map[string]interface{}
+	for i, v := range []*map[string]interface{}{&header, &claims} {
+		data, err := base64.RawURLEncoding.DecodeString(parts[i])
+		if err != nil || json.Unmarshal(data, v) != nil {
+			http.Error(w, `{"error":"invalid_grant"}`, http.StatusBadRequest)
+			return
+		}
+	}
+	s.mu.Lock()
+	s.header = header
+	s.claims = append(s.claims, claims)
+	s.mu.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"access_token": gdrivetest.AccessToken,
+		"token_type":   "Bearer",
+		"expires_in":   3600,
+	})
+}
+
+// writeKeyFile 寫入服務賬號密鑰文件（token_uri 指向模擬服務）
+func (s *fakeJWTServer) writeKeyFile(t *testing.T) string {
+	der, err := x509.MarshalPKCS8PrivateKey(s.key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := json.Marshal(map[string]interface{}{
+		"type":           "service_account",
+		"project_id":     "test-project",
+		"private_key_id": "test-key-id",
+		"private_key":    string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})),
+		"client_email":   "backup@test-project.iam.gserviceaccount.com",
+		"client_id":      "1234567890",
+		"token_uri":      s.URL + "/token",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "service-account.json")
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestServiceAccountJWTFlow(t *testing.T) {
+	tests := []struct {
+		name    string
+		subject string
+	}{
+		{"own drive", ""},
+		{"impersonate", "user@example.com"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			jwt := newFakeJWTServer(t)
+			drive := gdrivetest.NewServer()
+			defer drive.Close()
+
+			// 密鑰文件的 type 為 service_account 時使用 JWT 授權，不需要 TokenFile 和交互式授權
+			client, err := gdrive.NewClient(&gdrive.Config{
+				Enabled:            true,
+				CredentialsFile:    jwt.writeKeyFile(t),
+				ImpersonateSubject: tt.subject,
+				FolderName:         "backups",
+				Scopes:             []string{"drive"},
+				DevicePrompter:     failPrompter{t},
+				Logger:             testLogger{t},
+			}, gdrive.WithEndpoint(drive.URL()), gdrive.WithHTTPClient(drive.HTTPClient()))
+			if err != nil {
+				t.Fatalf("NewClient: %v", err)
+			}
+			status, err := client.AuthStatus()
+			if err != nil {
+				t.Fatalf("AuthStatus: %v", err)
+			}
+			if !status.ServiceAccount || status.HasRefreshToken {
+				t.Fatalf("AuthStatus = %+v, want service account without refresh token", status)
+			}
+			if n := len(drive.FindByName("backups")); n != 1 {
+				t.Fatalf("folders named backups = %d, want 1", n)
+			}
+
+			jwt.mu.Lock()
+			defer jwt.mu.Unlock()
+			if len(jwt.claims) == 0 {
+				t.Fatal("no JWT assertion received")
+			}
+			if jwt.header["alg"] != "RS256" || jwt.header["kid"] != "test-key-id" {
+				t.Fatalf("JWT header = %v, want RS256 with key test-key-id", jwt.header)
+			}
+			claims := jwt.claims[0]
+			want := map[string]string{
+				"iss":   "backup@test-project.iam.gserviceaccount.com",
+				"aud":   jwt.URL + "/token",
+				"scope": "https://www.googleapis.com/auth/drive",
+			}
+			for k, v := range want {
+				if claims[k] != v {
+					t.Fatalf("claim %s = %v, want %s", k, claims[k], v)
+				}
+			}
+			sub, ok := claims["sub"]
+			if tt.subject == "" && ok {
+				t.Fatalf("claim sub = %v, want none", sub)
+			}
+			if tt.subject != "" && sub != tt.subject {
+				t.Fatalf("claim sub = %v, want %s", sub, tt.subject)
+			}
+		})
+	}
+}
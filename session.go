@@ -0,0 +1,312 @@
+package gdrive
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+
+	"golang.org/x/oauth2"
+)
+
+// AuthState 客戶端授權狀態
+type AuthState int
+
+const (
+	// AuthStateAuthenticated 已授權，可正常訪問 Drive
+	AuthStateAuthenticated AuthState = iota
+
+	// AuthStateUnauthenticated 授權已失效（如 Refresh Token 被撤銷），需要重新授權
+	AuthStateUnauthenticated
+
+	// AuthStateReauthorizing 正在重新授權，請求會等待授權完成
+	AuthStateReauthorizing
+)
+
+// String 返回授權狀態名稱
+func (s AuthState) String() string {
+	switch s {
+	case AuthStateAuthenticated:
+		return "authenticated"
+	case AuthStateUnauthenticated:
+		return "unauthenticated"
+	case AuthStateReauthorizing:
+		return "reauthorizing"
+	default:
+		return fmt.Sprintf("AuthState(%d)", int(s))
+	}
+}
+
+// ReauthFunc 重新授權回調，在 Refresh Token 失效（invalid_grant）時調用
+// 返回 nil 表示繼續執行配置的交互式授權流程（Device Flow 或回環授權）；返回錯誤則放棄重新授權
+// 可用於通知運維人員、記錄告警，或在無人值守時拒絕重新授權
+type ReauthFunc func(ctx context.Context, cause error) error
+
+// authSession 認證會話，保存 HTTP 客戶端及其使用的 Token 來源
+type authSession struct {
+	httpClient     *http.Client // 已授權的 HTTP 客戶端
+	base           *http.Client // 底層 HTTP 客戶端（代理、TLS 等配置）
+	store          TokenStore   // 僅用戶授權模式下不為 nil
+	scopes         []string     // 請求的權限範圍
+	serviceAccount bool         // 是否為服務賬號模式
+
+	config      *Config
+	oauthConfig *oauth2.Config // 用戶授權模式的 OAuth2 配置，其他模式下為 nil
+
+	mu          sync.Mutex
+	tokenSource oauth2.TokenSource
+	state       AuthState
+	cause       error         // 進入未認證狀態的原因
+	reauthDone  chan struct{} // 重新授權結束時關閉
+}
+
+// newStaticSession 創建使用固定 TokenSource 的認證會話（服務賬號或外部提供的 TokenSource）
+// tokenSource 為 nil 時表示由外部 Drive Service 自行處理授權
+func newStaticSession(config *Config, tokenSource oauth2.TokenSource, scopes []string, base *http.Client, serviceAccount bool) *authSession {
+	session := &authSession{
+		config:         config,
+		base:           base,
+		tokenSource:    tokenSource,
+		scopes:         scopes,
+		serviceAccount: serviceAccount,
+	}
+	session.httpClient = session.newHTTPClient()
+	return session
+}
+
+// newUserSession 創建用戶授權認證會話
+func newUserSession(config *Config, oauthConfig *oauth2.Config, store TokenStore, token *oauth2.Token, base *http.Client) *authSession {
+	session := &authSession{
+		config:      config,
+		base:        base,
+		oauthConfig: oauthConfig,
+		store:       store,
+		scopes:      oauthConfig.Scopes,
+	}
+	session.tokenSource = session.newTokenSource(token)
+	session.httpClient = session.newHTTPClient()
+	return session
+}
+
+// newHTTPClient 基於底層 HTTP 客戶端創建帶授權頭的 HTTP 客戶端
+func (s *authSession) newHTTPClient() *http.Client {
+	transport := s.base.Transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+
+	httpClient := *s.base
+	httpClient.Transport = &authTransport{session: s, base: transport}
+	return &httpClient
+}
+
+// oauthContext 返回授權請求使用的 context（使用底層 HTTP 客戶端）
+func (s *authSession) oauthContext() context.Context {
+	return withHTTPClient(context.Background(), s.base)
+}
+
+// withHTTPClient 將 HTTP 客戶端附加到 context，供 oauth2 庫的授權請求使用
+func withHTTPClient(ctx context.Context, httpClient *http.Client) context.Context {
+	return context.WithValue(ctx, oauth2.HTTPClient, httpClient)
+}
+
+// newTokenSource 創建自動刷新並持久化的 TokenSource
+func (s *authSession) newTokenSource(token *oauth2.Token) oauth2.TokenSource {
+	persisting := newPersistingTokenSource(s.oauthConfig.TokenSource(s.oauthContext(), token), s.store, s.config, token)
+	return oauth2.ReuseTokenSource(token, persisting)
+}
+
+// authState 返回當前授權狀態
+func (s *authSession) authState() AuthState {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.state
+}
+
+// token 返回有效 Token
+// 正在重新授權時等待授權完成；Refresh Token 失效時進入未認證狀態並觸發重新授權
+func (s *authSession) token(ctx context.Context) (*oauth2.Token, error) {
+	_, token, err := s.sourceToken(ctx)
+	return token, err
+}
+
+// sourceToken 返回有效 Token 及其來源（用於在請求失敗時使對應的 TokenSource 失效）
+func (s *authSession) sourceToken(ctx context.Context) (oauth2.TokenSource, *oauth2.Token, error) {
+	for {
+		if err := s.waitReady(ctx); err != nil {
+			return nil, nil, err
+		}
+
+		s.mu.Lock()
+		source := s.tokenSource
+		s.mu.Unlock()
+
+		if source == nil {
+			return nil, nil, s.config.newError(CodeMissingTokenSource, nil)
+		}
+
+		token, err := source.Token()
+		if err == nil {
+			return source, token, nil
+		}
+		if !isInvalidGrant(err) {
+			return nil, nil, err
+		}
+
+		// Refresh Token 已失效，進入未認證狀態後重新等待
+		s.invalidate(source, err)
+	}
+}
+
+// waitReady 等待會話可用
+// 正在重新授權時阻塞直到授權結束；處於未認證狀態時返回錯誤
+func (s *authSession) waitReady(ctx context.Context) error {
+	for {
+		s.mu.Lock()
+		state, cause, done := s.state, s.cause, s.reauthDone
+		s.mu.Unlock()
+
+		switch state {
+		case AuthStateAuthenticated:
+			return nil
+		case AuthStateUnauthenticated:
+			return s.config.newError(CodeUnauthorized, cause)
+		}
+
+		select {
+		case <-done:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// invalidate 將會話標記為未認證，配置了重新授權回調時在後台啟動重新授權
+// source: 失效的 TokenSource，若已被替換則忽略（其他請求已處理）
+func (s *authSession) invalidate(source oauth2.TokenSource, cause error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.state != AuthStateAuthenticated || s.tokenSource != source {
+		return
+	}
+
+	s.state = AuthStateUnauthenticated
+	s.cause = cause
+	s.config.logger().Errorf(s.config.text(msgAuthInvalidated), cause)
+
+	if s.oauthConfig == nil || s.config.OnReauth == nil {
+		return
+	}
+	s.startReauthLocked(cause, true)
+}
+
+// loggedOut 將會話標記為已登出
+func (s *authSession) loggedOut() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.state = AuthStateUnauthenticated
+	s.cause = s.config.newError(CodeLoggedOut, nil)
+}
+
+// reauthorize 主動重新授權並等待完成
+func (s *authSession) reauthorize(ctx context.Context) error {
+	if s.oauthConfig == nil {
+		return s.config.newError(CodeReauthUnsupported, nil)
+	}
+
+	s.mu.Lock()
+	if s.state != AuthStateReauthorizing {
+		s.startReauthLocked(s.cause, false)
+	}
+	s.mu.Unlock()
+
+	return s.waitReady(ctx)
+}
+
+// startReauthLocked 在後台啟動重新授權（調用方需持有鎖）
+// useHook: 是否先調用 Config.OnReauth 回調
+func (s *authSession) startReauthLocked(cause error, useHook bool) {
+	s.state = AuthStateReauthorizing
+	s.reauthDone = make(chan struct{})
+
+	go func(done chan struct{}) {
+		token, err := s.runReauth(cause, useHook)
+
+		s.mu.Lock()
+		if err != nil {
+			s.state = AuthStateUnauthenticated
+			s.cause = err
+			s.config.logger().Errorf(s.config.text(msgReauthFailed), err)
+		} else {
+			s.state = AuthStateAuthenticated
+			s.cause = nil
+			s.tokenSource = s.newTokenSource(token)
+			s.config.logger().Infof(s.config.text(msgReauthSucceeded))
+		}
+		close(done)
+		s.mu.Unlock()
+	}(s.reauthDone)
+}
+
+// runReauth 執行重新授權回調和交互式授權流程，並保存新 Token
+func (s *authSession) runReauth(cause error, useHook bool) (*oauth2.Token, error) {
+	ctx := s.oauthContext()
+
+	if useHook {
+		if err := s.config.OnReauth(ctx, cause); err != nil {
+			return nil, s.config.newError(CodeReauthCanceled, err)
+		}
+	}
+
+	token, err := authorize(ctx, s.config, s.oauthConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.store.Save(token); err != nil {
+		return nil, s.config.newError(CodeSaveTokenFailed, err)
+	}
+	return token, nil
+}
+
+// isInvalidGrant 判斷錯誤是否為 Refresh Token 失效（invalid_grant）
+func isInvalidGrant(err error) bool {
+	var retrieveErr *oauth2.RetrieveError
+	return errors.As(err, &retrieveErr) && retrieveErr.ErrorCode == "invalid_grant"
+}
+
+// authTransport 為請求添加授權頭的 HTTP Transport
+// 在獲取 Token 時檢測 invalid_grant、在響應中檢測權限範圍不足，並在重新授權期間阻塞請求
+type authTransport struct {
+	session *authSession
+	base    http.RoundTripper
+}
+
+// RoundTrip 實現 http.RoundTripper
+func (t *authTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	source, token, err := t.session.sourceToken(req.Context())
+	if err != nil {
+		if req.Body != nil {
+			_ = req.Body.Close()
+		}
+		return nil, err
+	}
+
+	authReq := req.Clone(req.Context())
+	token.SetAuthHeader(authReq)
+	resp, err := t.base.RoundTrip(authReq)
+
+	// Token 實際缺少所需權限範圍（如授權時用戶取消勾選了部分範圍）
+	// 配置了 OnReauth 時與 invalid_grant 一樣重新授權；否則只返回 403，不使會話失效，其他請求仍可繼續
+	if err == nil && t.session.oauthConfig != nil && isInsufficientScope(resp) {
+		if t.session.config.OnReauth != nil {
+			t.session.invalidate(source, t.session.config.newError(CodeInsufficientScope, nil))
+		} else {
+			t.session.config.logger().Warningf(t.session.config.text(msgScopeInsufficient))
+		}
+	}
+	return resp, err
+}
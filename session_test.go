@@ -0,0 +1,237 @@
+package gdrive_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"golang.org/x/oauth2"
+
+	"github.com/Digman/gdrive"
+	"github.com/Digman/gdrive/gdrivetest"
+)
+
+// newSessionClient 創建使用回環授權的客戶端
+// 已保存的 Access Token 已過期，且新 Token 的有效期短於刷新提前量，每次請求都會使用 Refresh Token 刷新；
+// 重新授權時換取的 Refresh Token 為 "test-refresh-2"
+func newSessionClient(t *testing.T, oauth *fakeOAuth, drive *gdrivetest.Server, store gdrive.TokenStore, configure func(*gdrive.Config)) *gdrive.Client {
+	t.Helper()
+
+	oauth.mu.Lock()
+	oauth.expiresIn = 1
+	oauth.newRefresh = "test-refresh-2"
+	oauth.mu.Unlock()
+	oauth.setRefreshToken("test-refresh", true)
+
+	token := (&oauth2.Token{
+		AccessToken:  "expired",
+		RefreshToken: "test-refresh",
+		TokenType:    "Bearer",
+		Expiry:       time.Now().Add(-time.Hour),
+	}).WithExtra(map[string]interface{}{"scope": "https://www.googleapis.com/auth/drive"})
+	if err := store.Save(token); err != nil {
+		t.Fatal(err)
+	}
+
+	config := &gdrive.Config{
+		Enabled:         true,
+		CredentialsFile: oauth.writeCredentials(t),
+		TokenStore:      store,
+		FolderName:      "backups",
+		Scopes:          []string{"drive"},
+		AuthFlow:        gdrive.AuthFlowLoopback,
+		DisableBrowser:  true,
+		DevicePrompter: &browserPrompter{
+			t:      t,
+			oauth:  oauth,
+			query:  func(state string) url.Values { return url.Values{"state": {state}, "code": {"test-code"}} },
+			status: make(chan int, 1),
+		},
+		Logger: testLogger{t},
+	}
+	if configure != nil {
+		configure(config)
+	}
+
+	client, err := gdrive.NewClient(config, gdrive.WithEndpoint(drive.URL()), gdrive.WithHTTPClient(drive.HTTPClient()))
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	return client
+}
+
+// isInvalidGrant 判斷錯誤是否為 Refresh Token 失效
+func isInvalidGrant(err error) bool {
+	var retrieveErr *oauth2.RetrieveError
+	return errors.As(err, &retrieveErr) && retrieveErr.ErrorCode == "invalid_grant"
+}
+
+func TestSessionInvalidGrantWithoutReauth(t *testing.T) {
+	oauth := newFakeOAuth(t)
+	drive := gdrivetest.NewServer()
+	defer drive.Close()
+	store := gdrive.NewMemoryTokenStore(nil)
+	client := newSessionClient(t, oauth, drive, store, nil)
+
+	// Refresh Token 被撤銷：未配置 OnReauth 時進入未認證狀態，不啓動交互式授權
+	oauth.setRefreshToken("test-refresh", false)
+	_, err := client.Quota()
+	if !errors.Is(err, gdrive.ErrUnauthorized) || !isInvalidGrant(err) {
+		t.Fatalf("Quota = %v, want ErrUnauthorized caused by invalid_grant", err)
+	}
+	if state := client.AuthState(); state != gdrive.AuthStateUnauthenticated {
+		t.Fatalf("AuthState = %v, want unauthenticated", state)
+	}
+
+	// 後續請求直接返回錯誤，不再刷新
+	_, refreshed := oauth.counts()
+	if _, err := client.Quota(); !errors.Is(err, gdrive.ErrUnauthorized) {
+		t.Fatalf("Quota = %v, want ErrUnauthorized", err)
+	}
+	if exchanged, n := oauth.counts(); exchanged != 0 || n != refreshed {
+		t.Fatalf("exchanged = %d, refreshed = %d, want 0, %d", exchanged, n, refreshed)
+	}
+	if token, err := store.Load(); err != nil || token.RefreshToken != "test-refresh" {
+		t.Fatalf("stored token = %+v, %v, want unchanged", token, err)
+	}
+}
+
+func TestSessionReauthRecovers(t *testing.T) {
+	oauth := newFakeOAuth(t)
+	drive := gdrivetest.NewServer()
+	defer drive.Close()
+	store := gdrive.NewMemoryTokenStore(nil)
+
+	var causes []error
+	client := newSessionClient(t, oauth, drive, store, func(c *gdrive.Config) {
+		c.OnReauth = func(_ context.Context, cause error) error {
+			causes = append(causes, cause)
+			return nil
+		}
+	})
+
+	// 請求等待重新授權完成後繼續，新的 Refresh Token 被保存
+	oauth.setRefreshToken("test-refresh", false)
+	if _, err := client.Quota(); err != nil {
+		t.Fatalf("Quota: %v", err)
+	}
+	if len(causes) != 1 || !isInvalidGrant(causes[0]) {
+		t.Fatalf("OnReauth causes = %v, want one invalid_grant", causes)
+	}
+	if state := client.AuthState(); state != gdrive.AuthStateAuthenticated {
+		t.Fatalf("AuthState = %v, want authenticated", state)
+	}
+	if exchanged, _ := oauth.counts(); exchanged != 1 {
+		t.Fatalf("exchanged = %d, want 1", exchanged)
+	}
+	token, err := store.Load()
+	if err != nil || token.RefreshToken != "test-refresh-2" {
+		t.Fatalf("stored token = %+v, %v, want refresh token test-refresh-2", token, err)
+	}
+
+	// 之後的請求使用新的 Refresh Token 刷新
+	_, refreshed := oauth.counts()
+	if _, err := client.Quota(); err != nil {
+		t.Fatalf("Quota: %v", err)
+	}
+	if _, n := oauth.counts(); n <= refreshed {
+		t.Fatalf("refreshed = %d, want more than %d", n, refreshed)
+	}
+}
+
+func TestSessionReauthCanceled(t *testing.T) {
+	oauth := newFakeOAuth(t)
+	drive := gdrivetest.NewServer()
+	defer drive.Close()
+
+	denied := errors.New("denied")
+	client := newSessionClient(t, oauth, drive, gdrive.NewMemoryTokenStore(nil), func(c *gdrive.Config) {
+		c.OnReauth = func(context.Context, error) error { return denied }
+	})
+
+	oauth.setRefreshToken("test-refresh", false)
+	_, err := client.Quota()
+	if !errors.Is(err, gdrive.ErrUnauthorized) || !hasErrorCode(err, gdrive.CodeReauthCanceled) || !errors.Is(err, denied) {
+		t.Fatalf("Quota = %v, want ErrUnauthorized caused by %s", err, gdrive.CodeReauthCanceled)
+	}
+	if state := client.AuthState(); state != gdrive.AuthStateUnauthenticated {
+		t.Fatalf("AuthState = %v, want unauthenticated", state)
+	}
+	if exchanged, _ := oauth.counts(); exchanged != 0 {
+		t.Fatalf("exchanged = %d, want 0", exchanged)
+	}
+}
+
+func TestBackupWaitsForReauth(t *testing.T) {
+	oauth := newFakeOAuth(t)
+	drive := gdrivetest.NewServer()
+	defer drive.Close()
+
+	started, release := make(chan struct{}), make(chan struct{})
+	done := make(chan gdrive.BackupProgress, 1)
+	dir := writeBackupFiles(t, map[string]int{"a.bin": 100})
+	client := newSessionClient(t, oauth, drive, gdrive.NewMemoryTokenStore(nil), func(c *gdrive.Config) {
+		c.BackupEnabled = true
+		c.BackupInterval = time.Hour
+		c.BackupPaths = []string{dir}
+		c.OnBackupProgress = func(p gdrive.BackupProgress) {
+			if p.FilesTotal > 0 && p.FilesDone == p.FilesTotal {
+				select {
+				case done <- p:
+				default:
+				}
+			}
+		}
+		c.OnReauth = func(context.Context, error) error {
+			close(started)
+			<-release
+			return nil
+		}
+	})
+
+	// 請求觸發重新授權，OnReauth 返回前會話處於重新授權狀態
+	oauth.setRefreshToken("test-refresh", false)
+	quotaErr := make(chan error, 1)
+	go func() {
+		_, err := client.Quota()
+		quotaErr <- err
+	}()
+	<-started
+	if state := client.AuthState(); state != gdrive.AuthStateReauthorizing {
+		t.Fatalf("AuthState = %v, want reauthorizing", state)
+	}
+
+	// 重新授權期間啓動的備份等待授權完成，不上傳文件
+	drive.ResetRequests()
+	if err := client.StartBackup(); err != nil {
+		t.Fatalf("StartBackup: %v", err)
+	}
+	defer client.StopBackup()
+	time.Sleep(100 * time.Millisecond)
+	if n := len(drive.Requests()); n != 0 {
+		t.Fatalf("requests during reauth = %d, want 0", n)
+	}
+
+	// 授權完成後備份繼續
+	close(release)
+	if err := <-quotaErr; err != nil {
+		t.Fatalf("Quota: %v", err)
+	}
+	select {
+	case p := <-done:
+		if p.FilesTotal != 1 {
+			t.Fatalf("FilesTotal = %d, want 1", p.FilesTotal)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("備份未完成")
+	}
+	if n := countRequests(drive, http.MethodPost, "/upload/drive/v3/files"); n != 1 {
+		t.Fatalf("upload requests = %d, want 1", n)
+	}
+	if files := drive.FindByName("a.bin"); len(files) != 1 {
+		t.Fatalf("a.bin files = %d, want 1", len(files))
+	}
+}
@@ -0,0 +1,81 @@
+package gdrive
+
+import (
+	"context"
+
+	"google.golang.org/api/drive/v3"
+)
+
+// SharedDrive 共享雲端硬碟
+type SharedDrive struct {
+	ID   string // 共享雲端硬碟 ID
+	Name string // 共享雲端硬碟名稱
+}
+
+// ListSharedDrives 列出當前賬號可訪問的共享雲端硬碟
+// 返回: 共享雲端硬碟列表和錯誤信息
+func (c *Client) ListSharedDrives() ([]SharedDrive, error) {
+	return c.ListSharedDrivesContext(context.Background())
+}
+
+// ListSharedDrivesContext 列出當前賬號可訪問的共享雲端硬碟（支持 context 取消）
+func (c *Client) ListSharedDrivesContext(ctx context.Context) ([]SharedDrive, error) {
+	var drives []SharedDrive
+	pageToken := ""
+
+	for {
+		var driveList *drive.DriveList
+		err := c.retry(ctx, CodeListDrivesFailed, func() (err error) {
+			driveList, err = c.service.Drives.List().
+				Fields("nextPageToken, drives(id, name)").
+				PageSize(100).
+				PageToken(pageToken).
+				Context(ctx).
+				Do()
+			return err
+		})
+		if err != nil {
+			return nil, c.config.newError(CodeListDrivesFailed, err)
+		}
+
+		for _, d := range driveList.Drives {
+			drives = append(drives, SharedDrive{ID: d.Id, Name: d.Name})
+		}
+
+		if driveList.NextPageToken == "" {
+			return drives, nil
+		}
+		pageToken = driveList.NextPageToken
+	}
+}
+
+// resolveSharedDrive 根據配置確定目標共享雲端硬碟 ID
+// 配置了 SharedDriveID 時直接使用；僅配置了 SharedDriveName 時按名稱查找，同名的共享雲端硬碟不止一個時返回錯誤
+// 返回: 共享雲端硬碟 ID（未配置時為空字符串）和錯誤信息
+func (c *Client) resolveSharedDrive(ctx context.Context) (string, error) {
+	if c.config.SharedDriveID != "" {
+		return c.config.SharedDriveID, nil
+	}
+	if c.config.SharedDriveName == "" {
+		return "", nil
+	}
+
+	drives, err := c.ListSharedDrivesContext(ctx)
+	if err != nil {
+		return "", err
+	}
+	var driveID string
+	for _, d := range drives {
+		if d.Name != c.config.SharedDriveName {
+			continue
+		}
+		if driveID != "" {
+			return "", c.config.newError(CodeSharedDriveAmbiguous, nil, c.config.SharedDriveName)
+		}
+		driveID = d.ID
+	}
+	if driveID == "" {
+		return "", c.config.newError(CodeSharedDriveNotFound, nil, c.config.SharedDriveName)
+	}
+	return driveID, nil
+}
@@ -0,0 +1,158 @@
+package gdrive_test
+
+import (
+	"net/http"
+	"net/url"
+	"slices"
+	"testing"
+
+	"github.com/Digman/gdrive"
+	"github.com/Digman/gdrive/gdrivetest"
+)
+
+func TestSharedDriveName(t *testing.T) {
+	tests := []struct {
+		name   string
+		drives []string
+		code   gdrive.ErrorCode
+	}{
+		{"found", []string{"other", "team"}, ""},
+		{"not found", []string{"other"}, gdrive.CodeSharedDriveNotFound},
+		{"ambiguous", []string{"team", "team"}, gdrive.CodeSharedDriveAmbiguous},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := gdrivetest.NewServer()
+			defer srv.Close()
+			var driveID string
+			for _, name := range tt.drives {
+				id := srv.AddSharedDrive(name)
+				if name == "team" && driveID == "" {
+					driveID = id
+				}
+			}
+
+			client, err := srv.NewClient(&gdrive.Config{FolderName: "backups", SharedDriveName: "team", Logger: testLogger{t}})
+			if tt.code != "" {
+				if !hasErrorCode(err, tt.code) {
+					t.Fatalf("NewClient = %v, want %s", err, tt.code)
+				}
+				if n := len(srv.FindByName("backups")); n != 0 {
+					t.Fatalf("folders named backups = %d, want 0", n)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("NewClient: %v", err)
+			}
+			if got := client.SharedDriveID(); got != driveID {
+				t.Fatalf("SharedDriveID = %q, want %q", got, driveID)
+			}
+
+			// 備份文件夾創建在共享雲端硬碟的根目錄
+			folder, ok := srv.File(client.GetFolderID())
+			if !ok || folder.DriveID != driveID || !slices.Equal(folder.Parents, []string{driveID}) {
+				t.Fatalf("backup folder = %+v, want top-level folder in %s", folder, driveID)
+			}
+		})
+	}
+}
+
+func TestSharedDriveFoldersAndUploads(t *testing.T) {
+	srv := gdrivetest.NewServer()
+	defer srv.Close()
+	driveID := srv.AddSharedDrive("team")
+
+	// 我的雲端硬碟中的同名文件夾不應被使用
+	srv.AddFile(gdrivetest.File{Name: "backups", MimeType: gdrivetest.FolderMimeType})
+	srv.AddFile(gdrivetest.File{Name: "top", MimeType: gdrivetest.FolderMimeType})
+
+	client := newTestClient(t, srv, nil, func(c *gdrive.Config) { c.SharedDriveID = driveID })
+
+	inDrive := func(id string) {
+		t.Helper()
+		f, ok := srv.File(id)
+		if !ok || f.DriveID != driveID {
+			t.Fatalf("file %s = %+v, want in shared drive %s", id, f, driveID)
+		}
+	}
+	inDrive(client.GetFolderID())
+
+	// parentID 為空時創建在共享雲端硬碟的根目錄
+	topID, err := client.CreateFolder("top", "")
+	if err != nil {
+		t.Fatalf("CreateFolder: %v", err)
+	}
+	if top, _ := srv.File(topID); !slices.Equal(top.Parents, []string{driveID}) {
+		t.Fatalf("top parents = %v, want [%s]", top.Parents, driveID)
+	}
+
+	folderID, err := client.MkdirAll("backups/db/daily")
+	if err != nil {
+		t.Fatalf("MkdirAll: %v", err)
+	}
+	inDrive(folderID)
+	daily, _ := srv.File(folderID)
+	db, _ := srv.File(daily.Parents[0])
+	if !slices.Equal(db.Parents, []string{client.GetFolderID()}) {
+		t.Fatalf("db parents = %v, want backup folder %s", db.Parents, client.GetFolderID())
+	}
+
+	localPath := writeTempFile(t, "a.txt", []byte("hello"))
+	fileID, err := client.UploadFile(localPath)
+	if err != nil {
+		t.Fatalf("UploadFile: %v", err)
+	}
+	inDrive(fileID)
+	updatedID, created, err := client.UploadOrUpdateFile(localPath)
+	if err != nil || created || updatedID != fileID {
+		t.Fatalf("UploadOrUpdateFile = %s, %v, %v, want update of %s", updatedID, created, err, fileID)
+	}
+
+	// 所有列表查詢都限定在該共享雲端硬碟中
+	var lists int
+	for _, r := range srv.Requests() {
+		if r.Method != http.MethodGet || r.Path != "/drive/v3/files" {
+			continue
+		}
+		lists++
+		q, err := url.ParseQuery(r.Query)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if q.Get("corpora") != "drive" || q.Get("driveId") != driveID || q.Get("includeItemsFromAllDrives") != "true" {
+			t.Fatalf("list query = %s, want corpora=drive and driveId=%s", r.Query, driveID)
+		}
+	}
+	if lists == 0 {
+		t.Fatal("no list requests")
+	}
+}
+
+func TestSharedDriveBackupSkipsQuotaCheck(t *testing.T) {
+	srv := gdrivetest.NewServer()
+	defer srv.Close()
+	driveID := srv.AddSharedDrive("team")
+	srv.SetQuota(100)
+
+	// 共享雲端硬碟不計入用戶配額：超出配額的文件也不推遲，且不查詢配額
+	dir := writeBackupFiles(t, map[string]int{"a.bin": 800})
+	progress := runBackupOnce(t, srv, &gdrive.Config{
+		FolderName:        "backups",
+		SharedDriveID:     driveID,
+		BackupPaths:       []string{dir},
+		BackupQuotaPolicy: gdrive.QuotaPolicyDefer,
+	})
+
+	if len(progress.Deferred) != 0 || progress.FilesTotal != 1 {
+		t.Fatalf("progress = %+v, want a.bin uploaded", progress)
+	}
+	if n := countRequests(srv, http.MethodGet, "/drive/v3/about"); n != 0 {
+		t.Fatalf("about requests = %d, want 0", n)
+	}
+	files := srv.FindByName("a.bin")
+	if len(files) != 1 || files[0].DriveID != driveID {
+		t.Fatalf("a.bin files = %+v, want one file in %s", files, driveID)
+	}
+}
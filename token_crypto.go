@@ -0,0 +1,142 @@
+package gdrive
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"os"
+	"strings"
+)
+
+const (
+	tokenEnvelopeFormat = "gdrive-token-v1"       // 加密 Token 文件格式標識
+	tokenKDF            = "pbkdf2-sha256"         // 密鑰派生算法
+	tokenKDFIterations  = 600000                  // PBKDF2 迭代次數
+	tokenMinIterations  = 100000                  // 解密時接受的最小迭代次數
+	tokenMaxIterations  = 10 * tokenKDFIterations // 解密時接受的最大迭代次數（避免被篡改的文件佔滿 CPU）
+	tokenKeyLength      = 32                      // AES-256 密鑰長度
+	tokenSaltLength     = 16                      // 鹽長度
+)
+
+// TokenEncryption Token 文件加密配置（AES-256-GCM）
+// 密鑰來源按以下優先級選擇第一個非空項：Passphrase、PassphraseEnv、KeyFile
+type TokenEncryption struct {
+	Passphrase    string // 口令
+	PassphraseEnv string // 保存口令的環境變量名
+	KeyFile       string // 密鑰文件路徑（文件內容作為口令，首尾空白會被忽略）
+}
+
+// tokenEnvelope 加密 Token 文件結構
+type tokenEnvelope struct {
+	Format     string `json:"format"`
+	KDF        string `json:"kdf"`
+	Iterations int    `json:"iterations"`
+	Salt       []byte `json:"salt"`
+	Nonce      []byte `json:"nonce"`
+	Ciphertext []byte `json:"ciphertext"`
+}
+
+// secret 解析加密口令
+// language: 錯誤信息語言（下同）
+func (e *TokenEncryption) secret(language Language) (string, error) {
+	switch {
+	case e.Passphrase != "":
+		return e.Passphrase, nil
+	case e.PassphraseEnv != "":
+		value := os.Getenv(e.PassphraseEnv)
+		if value == "" {
+			return "", language.newError(CodeMissingPassphraseEnv, nil, e.PassphraseEnv)
+		}
+		return value, nil
+	case e.KeyFile != "":
+		data, err := os.ReadFile(e.KeyFile)
+		if err != nil {
+			return "", language.newError(CodeReadKeyFile, err)
+		}
+		value := strings.TrimSpace(string(data))
+		if value == "" {
+			return "", language.newError(CodeEmptyKeyFile, nil, e.KeyFile)
+		}
+		return value, nil
+	default:
+		return "", language.newError(CodeMissingEncryptionKey, nil)
+	}
+}
+
+// newGCM 根據口令和鹽派生密鑰並創建 AES-GCM 實例
+func newGCM(secret string, salt []byte, iterations int, language Language) (cipher.AEAD, error) {
+	key, err := pbkdf2.Key(sha256.New, secret, salt, iterations, tokenKeyLength)
+	if err != nil {
+		return nil, language.newError(CodeDeriveKeyFailed, err)
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// encrypt 加密 Token 明文
+func (e *TokenEncryption) encrypt(plaintext []byte, language Language) (*tokenEnvelope, error) {
+	secret, err := e.secret(language)
+	if err != nil {
+		return nil, err
+	}
+
+	salt := make([]byte, tokenSaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, language.newError(CodeRandomFailed, err)
+	}
+
+	gcm, err := newGCM(secret, salt, tokenKDFIterations, language)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, language.newError(CodeRandomFailed, err)
+	}
+
+	return &tokenEnvelope{
+		Format:     tokenEnvelopeFormat,
+		KDF:        tokenKDF,
+		Iterations: tokenKDFIterations,
+		Salt:       salt,
+		Nonce:      nonce,
+		Ciphertext: gcm.Seal(nil, nonce, plaintext, []byte(tokenEnvelopeFormat)),
+	}, nil
+}
+
+// decrypt 解密 Token 文件內容
+func (e *TokenEncryption) decrypt(envelope *tokenEnvelope, language Language) ([]byte, error) {
+	if envelope.Format != tokenEnvelopeFormat || envelope.KDF != tokenKDF {
+		return nil, language.newError(CodeUnsupportedTokenFormat, nil, envelope.Format, envelope.KDF)
+	}
+	if envelope.Iterations < tokenMinIterations || envelope.Iterations > tokenMaxIterations {
+		return nil, language.newError(CodeInvalidTokenIterations, nil, envelope.Iterations)
+	}
+
+	secret, err := e.secret(language)
+	if err != nil {
+		return nil, err
+	}
+
+	gcm, err := newGCM(secret, envelope.Salt, envelope.Iterations, language)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(envelope.Nonce) != gcm.NonceSize() {
+		return nil, language.newError(CodeCorruptTokenFile, nil)
+	}
+
+	plaintext, err := gcm.Open(nil, envelope.Nonce, envelope.Ciphertext, []byte(tokenEnvelopeFormat))
+	if err != nil {
+		return nil, language.newError(CodeDecryptTokenFailed, nil)
+	}
+	return plaintext, nil
+}
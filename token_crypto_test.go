@@ -0,0 +1,84 @@
+package gdrive
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"golang.org/x/oauth2"
+)
+
+func TestEncryptedTokenRoundTrip(t *testing.T) {
+	store := &FileTokenStore{
+		Path:       filepath.Join(t.TempDir(), "token.json"),
+		Encryption: &TokenEncryption{Passphrase: "secret"},
+	}
+	if err := store.Save(&oauth2.Token{AccessToken: "access", RefreshToken: "refresh"}); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	info, err := os.Stat(store.Path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if perm := info.Mode().Perm(); perm != 0o600 {
+		t.Fatalf("Token 文件權限 = %o, want 600", perm)
+	}
+
+	token, err := store.Load()
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if token.RefreshToken != "refresh" {
+		t.Fatalf("RefreshToken = %q", token.RefreshToken)
+	}
+
+	store.Encryption = &TokenEncryption{Passphrase: "wrong"}
+	if _, err := store.Load(); err == nil {
+		t.Fatal("錯誤的口令應解密失敗")
+	}
+}
+
+func TestDecryptRejectsTamperedIterations(t *testing.T) {
+	encryption := &TokenEncryption{Passphrase: "secret"}
+	envelope, err := encryption.encrypt([]byte(`{"access_token":"access"}`), LanguageEnglish)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		iterations int
+	}{
+		{"zero", 0},
+		{"negative", -1},
+		{"too small", tokenMinIterations - 1},
+		{"too large", tokenMaxIterations + 1},
+		{"huge", 1 << 31},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tampered := *envelope
+			tampered.Iterations = tt.iterations
+
+			// 經過 JSON 序列化，與從文件讀取的路徑一致
+			data, err := json.Marshal(&tampered)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var loaded tokenEnvelope
+			if err := json.Unmarshal(data, &loaded); err != nil {
+				t.Fatal(err)
+			}
+			_, err = encryption.decrypt(&loaded, LanguageEnglish)
+			if ErrorCodeOf(err) != CodeInvalidTokenIterations {
+				t.Fatalf("迭代次數 %d: err = %v, want %s", tt.iterations, err, CodeInvalidTokenIterations)
+			}
+			if want := fmt.Sprintf("invalid iteration count in encrypted token file: %d", tt.iterations); err.Error() != want {
+				t.Fatalf("err = %q, want %q", err, want)
+			}
+		})
+	}
+}
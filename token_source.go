@@ -0,0 +1,79 @@
+package gdrive
+
+import (
+	"sync"
+
+	"golang.org/x/oauth2"
+)
+
+// persistingTokenSource 包裝 TokenSource，在 Token 刷新後自動寫回 Token 存儲
+// 避免重啟後使用過期的 Access Token，以及丟失輪換後的 Refresh Token
+type persistingTokenSource struct {
+	mu     sync.Mutex
+	base   oauth2.TokenSource
+	store  TokenStore
+	config *Config
+	last   *oauth2.Token // 上次保存的 Token
+}
+
+// newPersistingTokenSource 創建自動持久化的 TokenSource
+// token: 當前已保存的 Token
+func newPersistingTokenSource(base oauth2.TokenSource, store TokenStore, config *Config, token *oauth2.Token) *persistingTokenSource {
+	return &persistingTokenSource{
+		base:   base,
+		store:  store,
+		config: config,
+		last:   token,
+	}
+}
+
+// Token 返回有效 Token；若 Token 已被刷新則保存新 Token
+func (s *persistingTokenSource) Token() (*oauth2.Token, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	token, err := s.base.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	if !tokenChanged(s.last, token) {
+		return token, nil
+	}
+
+	// 服務端未返回新的 Refresh Token 時沿用舊值
+	if token.RefreshToken == "" && s.last != nil && s.last.RefreshToken != "" {
+		refreshed := *token
+		refreshed.RefreshToken = s.last.RefreshToken
+		token = &refreshed
+	}
+
+	// 刷新響應未返回權限範圍時沿用舊值
+	if tokenScopes(token) == nil && s.last != nil {
+		if scopes := tokenScopes(s.last); scopes != nil {
+			token = withScopes(token, scopes)
+		}
+	}
+
+	s.config.logger().Infof(s.config.text(msgTokenRefreshed), token.Expiry.Format("2006-01-02 15:04:05"))
+
+	if err := s.store.Save(token); err != nil {
+		// 保存失敗不影響本次請求，下次刷新時會再次嘗試保存
+		s.config.logger().Warningf(s.config.text(msgTokenSaveFailed), err)
+		return token, nil
+	}
+
+	s.last = token
+	return token, nil
+}
+
+// tokenChanged 判斷 Token 是否與上次保存的不同
+func tokenChanged(old, current *oauth2.Token) bool {
+	if old == nil {
+		return true
+	}
+	if current.AccessToken != old.AccessToken {
+		return true
+	}
+	return current.RefreshToken != "" && current.RefreshToken != old.RefreshToken
+}
@@ -0,0 +1,128 @@
+package gdrive
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/oauth2"
+	"google.golang.org/api/drive/v3"
+)
+
+// staticTokenSource 總是返回同一個 Token
+type staticTokenSource struct {
+	token *oauth2.Token
+}
+
+func (s staticTokenSource) Token() (*oauth2.Token, error) { return s.token, nil }
+
+// countingStore 記錄保存次數的 Token 存儲，err 不為 nil 時保存失敗
+type countingStore struct {
+	MemoryTokenStore
+	saves int
+	err   error
+}
+
+func (s *countingStore) Save(token *oauth2.Token) error {
+	s.saves++
+	if s.err != nil {
+		return s.err
+	}
+	return s.MemoryTokenStore.Save(token)
+}
+
+// recordLogger 記錄警告日志
+type recordLogger struct {
+	testLogger
+	warnings []string
+}
+
+func (l *recordLogger) Warningf(format string, v ...interface{}) {
+	l.warnings = append(l.warnings, fmt.Sprintf(format, v...))
+}
+
+func TestPersistingTokenSourceSavesRefreshedToken(t *testing.T) {
+	last := withScopes(&oauth2.Token{AccessToken: "old", RefreshToken: "refresh", Expiry: time.Now()}, []string{drive.DriveScope})
+
+	tests := []struct {
+		name        string
+		refreshed   *oauth2.Token
+		wantRefresh string
+		wantScopes  []string
+	}{
+		{"keeps refresh token and scope", &oauth2.Token{AccessToken: "new"}, "refresh", []string{drive.DriveScope}},
+		{"rotated refresh token", &oauth2.Token{AccessToken: "new", RefreshToken: "rotated"}, "rotated", []string{drive.DriveScope}},
+		{"returned scope", withScopes(&oauth2.Token{AccessToken: "new"}, []string{drive.DriveFileScope}), "refresh", []string{drive.DriveFileScope}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := &countingStore{}
+			source := newPersistingTokenSource(staticTokenSource{tt.refreshed}, store, &Config{Logger: testLogger{t}}, last)
+
+			token, err := source.Token()
+			if err != nil {
+				t.Fatalf("Token: %v", err)
+			}
+			saved, err := store.Load()
+			if err != nil {
+				t.Fatalf("刷新後的 Token 未保存: %v", err)
+			}
+			for name, got := range map[string]*oauth2.Token{"returned": token, "saved": saved} {
+				if got.AccessToken != "new" || got.RefreshToken != tt.wantRefresh {
+					t.Fatalf("%s token = %s/%s, want new/%s", name, got.AccessToken, got.RefreshToken, tt.wantRefresh)
+				}
+				if scopes := tokenScopes(got); strings.Join(scopes, " ") != strings.Join(tt.wantScopes, " ") {
+					t.Fatalf("%s scopes = %v, want %v", name, scopes, tt.wantScopes)
+				}
+			}
+
+			// Token 未再變化時不重複保存
+			if _, err := source.Token(); err != nil {
+				t.Fatalf("Token: %v", err)
+			}
+			if store.saves != 1 {
+				t.Fatalf("saves = %d, want 1", store.saves)
+			}
+		})
+	}
+}
+
+func TestPersistingTokenSourceUnchangedToken(t *testing.T) {
+	token := &oauth2.Token{AccessToken: "access", RefreshToken: "refresh"}
+	store := &countingStore{}
+	source := newPersistingTokenSource(staticTokenSource{token}, store, &Config{Logger: testLogger{t}}, token)
+
+	if _, err := source.Token(); err != nil {
+		t.Fatalf("Token: %v", err)
+	}
+	if store.saves != 0 {
+		t.Fatalf("saves = %d, want 0", store.saves)
+	}
+}
+
+func TestPersistingTokenSourceSaveFailure(t *testing.T) {
+	last := &oauth2.Token{AccessToken: "old", RefreshToken: "refresh"}
+	store := &countingStore{err: errors.New("disk full")}
+	logger := &recordLogger{testLogger: testLogger{t}}
+	source := newPersistingTokenSource(staticTokenSource{&oauth2.Token{AccessToken: "new"}}, store, &Config{Logger: logger}, last)
+
+	// 保存失敗只記錄警告，不影響本次請求
+	token, err := source.Token()
+	if err != nil || token.AccessToken != "new" {
+		t.Fatalf("Token = %v, %v, want new token", token, err)
+	}
+	if len(logger.warnings) != 1 || !strings.Contains(logger.warnings[0], "disk full") {
+		t.Fatalf("warnings = %q, want save failure", logger.warnings)
+	}
+
+	// 下次獲取 Token 時再次嘗試保存
+	store.err = nil
+	if _, err := source.Token(); err != nil {
+		t.Fatalf("Token: %v", err)
+	}
+	if saved, err := store.Load(); store.saves != 2 || err != nil || saved.AccessToken != "new" {
+		t.Fatalf("saves = %d, stored = %v, %v, want new token saved on retry", store.saves, saved, err)
+	}
+}
@@ -0,0 +1,117 @@
+package gdrive
+
+import (
+	"errors"
+	"os"
+	"sync"
+
+	"golang.org/x/oauth2"
+)
+
+// TokenStore Token 存儲接口，用於加載、保存和刪除 OAuth2 Token
+// 可通過 Config.TokenStore 替換默認的文件存儲（如只讀或臨時文件系統的容器環境）
+type TokenStore interface {
+	// Load 加載 Token
+	// Token 不存在時返回 errors.Is(err, ErrNotFound) 或 errors.Is(err, os.ErrNotExist) 為 true 的錯誤，NewClient 會重新授權；
+	// 其他錯誤（如解密失敗）直接返回給 NewClient 的調用方，不會重新授權覆蓋已保存的 Token
+	Load() (*oauth2.Token, error)
+
+	// Save 保存 Token（覆蓋已有 Token）
+	Save(token *oauth2.Token) error
+
+	// Delete 刪除已保存的 Token；Token 不存在時不返回錯誤
+	Delete() error
+}
+
+// FileTokenStore 基於本地文件的 Token 存儲（默認實現）
+type FileTokenStore struct {
+	Path       string           // Token 文件路徑
+	Encryption *TokenEncryption // 加密配置（可選，nil 則以明文 JSON 保存）
+	Language   Language         // 錯誤信息語言（可選，默認繁體中文）
+}
+
+// NewFileTokenStore 創建基於文件的 Token 存儲
+func NewFileTokenStore(path string) *FileTokenStore {
+	return &FileTokenStore{Path: path}
+}
+
+// Load 從文件加載 Token
+func (s *FileTokenStore) Load() (*oauth2.Token, error) {
+	return loadToken(s.Path, s.Encryption, s.Language)
+}
+
+// Save 保存 Token 到文件
+func (s *FileTokenStore) Save(token *oauth2.Token) error {
+	if token == nil {
+		return s.Language.newError(CodeNilToken, nil)
+	}
+	return saveToken(s.Path, token, s.Encryption, s.Language)
+}
+
+// Delete 刪除 Token 文件
+func (s *FileTokenStore) Delete() error {
+	if err := os.Remove(s.Path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return s.Language.newError(CodeDeleteTokenFailed, err)
+	}
+	return nil
+}
+
+// MemoryTokenStore 基於內存的 Token 存儲，進程退出後 Token 丟失
+// 適用於只讀文件系統或由外部注入 Token 的場景
+type MemoryTokenStore struct {
+	Language Language // 錯誤信息語言（可選，默認繁體中文）
+
+	mu    sync.Mutex
+	token *oauth2.Token
+}
+
+// NewMemoryTokenStore 創建內存 Token 存儲
+// token: 初始 Token（可選，nil 表示首次使用時需要授權）
+func NewMemoryTokenStore(token *oauth2.Token) *MemoryTokenStore {
+	return &MemoryTokenStore{token: token}
+}
+
+// Load 返回內存中的 Token
+func (s *MemoryTokenStore) Load() (*oauth2.Token, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.token == nil {
+		return nil, s.Language.newError(CodeTokenNotFound, nil)
+	}
+
+	// 返回副本，避免調用方修改內部狀態
+	token := *s.token
+	return &token, nil
+}
+
+// Save 保存 Token 到內存
+func (s *MemoryTokenStore) Save(token *oauth2.Token) error {
+	if token == nil {
+		return s.Language.newError(CodeNilToken, nil)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	copied := *token
+	s.token = &copied
+	return nil
+}
+
+// Delete 清除內存中的 Token
+func (s *MemoryTokenStore) Delete() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.token = nil
+	return nil
+}
+
+// tokenStore 返回配置使用的 Token 存儲（未設置時使用 TokenFile 對應的文件存儲）
+func (c *Config) tokenStore() TokenStore {
+	if c.TokenStore != nil {
+		return c.TokenStore
+	}
+	return &FileTokenStore{Path: c.TokenFile, Encryption: c.TokenEncryption, Language: c.language()}
+}
@@ -0,0 +1,53 @@
+package gdrive
+
+import (
+	"path/filepath"
+	"testing"
+
+	"golang.org/x/oauth2"
+)
+
+func TestTokenStoresRejectNilToken(t *testing.T) {
+	stores := map[string]TokenStore{
+		"file":   NewFileTokenStore(filepath.Join(t.TempDir(), "token.json")),
+		"memory": NewMemoryTokenStore(nil),
+	}
+	for name, store := range stores {
+		t.Run(name, func(t *testing.T) {
+			if err := store.Save(nil); ErrorCodeOf(err) != CodeNilToken {
+				t.Fatalf("Save(nil) = %v, want %s", err, CodeNilToken)
+			}
+			if _, err := store.Load(); err == nil {
+				t.Fatal("Save(nil) 後不應存在 Token")
+			}
+		})
+	}
+}
+
+func TestTokenStoresRoundTrip(t *testing.T) {
+	stores := map[string]TokenStore{
+		"file":   NewFileTokenStore(filepath.Join(t.TempDir(), "token.json")),
+		"memory": NewMemoryTokenStore(nil),
+	}
+	for name, store := range stores {
+		t.Run(name, func(t *testing.T) {
+			want := &oauth2.Token{AccessToken: "access", RefreshToken: "refresh", TokenType: "Bearer"}
+			if err := store.Save(want); err != nil {
+				t.Fatalf("Save: %v", err)
+			}
+			got, err := store.Load()
+			if err != nil {
+				t.Fatalf("Load: %v", err)
+			}
+			if got.AccessToken != want.AccessToken || got.RefreshToken != want.RefreshToken {
+				t.Fatalf("Load = %+v, want %+v", got, want)
+			}
+			if err := store.Delete(); err != nil {
+				t.Fatalf("Delete: %v", err)
+			}
+			if _, err := store.Load(); err == nil {
+				t.Fatal("Delete 後不應存在 Token")
+			}
+		})
+	}
+}
@@ -0,0 +1,167 @@
+package gdrive
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// uploadSessionTTL 可續傳上傳會話的有效期（Drive 會話約一周後失效，提前丟棄避免無效的狀態查詢）
+const uploadSessionTTL = 6 * 24 * time.Hour
+
+// journalEntry 續傳日志中記錄的未完成上傳
+type journalEntry struct {
+	SessionURI string    `json:"session_uri"`         // 可續傳上傳會話地址
+	Offset     int64     `json:"offset"`              // 服務端已確認接收的字節數
+	Size       int64     `json:"size"`                // 本地文件大小
+	ModTime    time.Time `json:"mod_time"`            // 本地文件修改時間
+	Name       string    `json:"name"`                // 文件名
+	FolderID   string    `json:"folder_id,omitempty"` // 創建文件時的目標文件夾 ID
+	FileID     string    `json:"file_id,omitempty"`   // 更新文件時的目標文件 ID
+	StartedAt  time.Time `json:"started_at"`          // 會話創建時間
+}
+
+// matches 判斷記錄是否可用於續傳（本地文件未變化、上傳目標一致且會話未過期）
+func (e *journalEntry) matches(target *journalEntry, now time.Time) bool {
+	return e.SessionURI != "" &&
+		e.Size == target.Size &&
+		e.ModTime.Equal(target.ModTime) &&
+		e.Name == target.Name &&
+		e.FolderID == target.FolderID &&
+		e.FileID == target.FileID &&
+		now.Sub(e.StartedAt) < uploadSessionTTL
+}
+
+// uploadJournal 續傳日志，以本地文件絕對路徑為鍵保存未完成的上傳會話
+// 會話地址無需授權即可寫入，文件權限為 0600
+type uploadJournal struct {
+	path string
+	mu   sync.Mutex
+}
+
+// newUploadJournal 創建續傳日志（path 為空時返回 nil，不記錄）
+func newUploadJournal(path string) *uploadJournal {
+	if path == "" {
+		return nil
+	}
+	return &uploadJournal{path: path}
+}
+
+// load 讀取指定文件的未完成上傳
+func (j *uploadJournal) load(key string) (*journalEntry, error) {
+	if j == nil {
+		return nil, nil
+	}
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	entries, err := j.read()
+	if err != nil {
+		return nil, err
+	}
+	entry, ok := entries[key]
+	if !ok {
+		return nil, nil
+	}
+	return &entry, nil
+}
+
+// save 保存指定文件的上傳進度
+func (j *uploadJournal) save(key string, entry *journalEntry) error {
+	if j == nil {
+		return nil
+	}
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	entries, err := j.read()
+	if err != nil {
+		// 日志損壞時重建，避免一直無法記錄
+		entries = make(map[string]journalEntry)
+	}
+
+	// 順便清理過期的會話
+	now := time.Now()
+	for k, e := range entries {
+		if now.Sub(e.StartedAt) >= uploadSessionTTL {
+			delete(entries, k)
+		}
+	}
+	entries[key] = *entry
+	return j.write(entries)
+}
+
+// remove 刪除指定文件的記錄（上傳完成或會話失效時）
+func (j *uploadJournal) remove(key string) error {
+	if j == nil {
+		return nil
+	}
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	entries, err := j.read()
+	if err != nil {
+		return err
+	}
+	if _, ok := entries[key]; !ok {
+		return nil
+	}
+	delete(entries, key)
+	return j.write(entries)
+}
+
+// read 讀取所有記錄（文件不存在時返回空記錄）
+func (j *uploadJournal) read() (map[string]journalEntry, error) {
+	entries := make(map[string]journalEntry)
+
+	data, err := os.ReadFile(j.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return entries, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// write 原子寫入所有記錄（沒有記錄時刪除日志文件）
+func (j *uploadJournal) write(entries map[string]journalEntry) error {
+	if len(entries) == 0 {
+		err := os.Remove(j.path)
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+
+	data, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	dir := filepath.Dir(j.path)
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(j.path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), j.path)
+}
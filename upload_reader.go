@@ -0,0 +1,179 @@
+package gdrive
+
+import (
+	"context"
+	"errors"
+	"io"
+
+	"google.golang.org/api/drive/v3"
+	"google.golang.org/api/googleapi"
+)
+
+// UploadOptions 從 io.Reader 上傳時的可選參數
+type UploadOptions struct {
+	MimeType    string // MIME 類型（可選，為空時由 Drive 根據內容和文件名識別）
+	Description string // 文件描述（可選）
+	FolderID    string // 目標文件夾 ID（可選，為空時使用配置的文件夾）
+
+	// OnProgress 本次上傳的進度回調（可選，與 Config.OnProgress 同時生效）
+	OnProgress ProgressFunc
+}
+
+// folderID 返回目標文件夾 ID
+func (o *UploadOptions) folderID(c *Client) string {
+	if o != nil && o.FolderID != "" {
+		return o.FolderID
+	}
+	return c.folderID
+}
+
+// metadata 返回上傳時附帶的文件元數據
+func (o *UploadOptions) metadata() *drive.File {
+	if o == nil {
+		return &drive.File{}
+	}
+	return &drive.File{
+		MimeType:    o.MimeType,
+		Description: o.Description,
+	}
+}
+
+// onProgress 返回本次上傳的進度回調
+func (o *UploadOptions) onProgress() ProgressFunc {
+	if o == nil {
+		return nil
+	}
+	return o.OnProgress
+}
+
+// mediaOptions 返回上傳內容的選項
+func (o *UploadOptions) mediaOptions() []googleapi.MediaOption {
+	if o == nil || o.MimeType == "" {
+		return nil
+	}
+	return []googleapi.MediaOption{googleapi.ContentType(o.MimeType)}
+}
+
+// UploadReader 從 io.Reader 上傳內容並創建新文件
+// 支持未知長度的 r（如管道、標准輸入）；r 實現 io.Seeker 時失敗會從起始位置重新上傳，否則不重試整個上傳
+// name: 文件名
+// r: 文件內容
+// opts: 可選參數（可為 nil）
+// 返回: 文件 ID 和錯誤信息（文件已創建但內容校驗失敗等情況下同時返回文件 ID）
+func (c *Client) UploadReader(ctx context.Context, name string, r io.Reader, opts *UploadOptions) (string, error) {
+	meta := opts.metadata()
+	meta.Name = name
+	meta.Parents = []string{opts.folderID(c)}
+
+	progress := c.config.newTransferProgress(name, readerSize(r), opts.onProgress())
+	return c.createFromReader(ctx, meta, r, progress, opts.mediaOptions()...)
+}
+
+// UploadOrUpdateReader 從 io.Reader 上傳內容：目標文件夾中不存在同名文件則創建，存在則更新
+// name: 文件名
+// r: 文件內容
+// opts: 可選參數（可為 nil）
+// 返回: 文件 ID、是否為新創建、錯誤信息
+func (c *Client) UploadOrUpdateReader(ctx context.Context, name string, r io.Reader, opts *UploadOptions) (string, bool, error) {
+	fileID, err := c.findFileByName(ctx, name, opts.folderID(c))
+	if errors.Is(err, ErrNotFound) {
+		// 文件不存在，執行上傳
+		fileID, err := c.UploadReader(ctx, name, r, opts)
+		if err != nil {
+			return fileID, fileID != "", err
+		}
+		return fileID, true, nil
+	}
+	if err != nil {
+		// 查詢失敗時不能確定文件是否存在，直接返回避免創建重複文件
+		return "", false, c.config.newError(CodeFindFileFailed, err)
+	}
+
+	// 文件已存在，執行更新
+	progress := c.config.newTransferProgress(name, readerSize(r), opts.onProgress())
+	fileID, err = c.updateFromReader(ctx, fileID, opts.metadata(), r, progress, opts.mediaOptions()...)
+	return fileID, false, err
+}
+
+// createFromReader 使用 r 的內容創建文件
+func (c *Client) createFromReader(ctx context.Context, meta *drive.File, r io.Reader, progress *transferProgress, options ...googleapi.MediaOption) (string, error) {
+	return c.uploadFromReader(ctx, CodeUploadFailed, "", meta, r, progress, options...)
+}
+
+// updateFromReader 使用 r 的內容更新文件
+func (c *Client) updateFromReader(ctx context.Context, fileID string, meta *drive.File, r io.Reader, progress *transferProgress, options ...googleapi.MediaOption) (string, error) {
+	return c.uploadFromReader(ctx, CodeUpdateFailed, fileID, meta, r, progress, options...)
+}
+
+// uploadFromReader 使用 r 的內容創建（fileID 為空）或更新文件
+// 上傳時計算發送內容的 MD5 和 SHA-256，上傳請求中清除舊的 SHA-256，校驗通過後再保存新值
+// MD5 與 Drive 返回的 md5Checksum 不一致時按重試策略重新上傳，已創建的文件改為覆蓋其內容，不會產生重複文件
+// 創建請求在服務端可能已處理的錯誤（5xx、超時）下不重試，避免產生重複文件
+// 文件已創建後失敗（如最後一次上傳仍校驗失敗）時返回文件 ID 和錯誤，調用方可刪除或重新上傳
+func (c *Client) uploadFromReader(ctx context.Context, op ErrorCode, fileID string, meta *drive.File, r io.Reader, progress *transferProgress, options ...googleapi.MediaOption) (string, error) {
+	policy := c.config.retryPolicy()
+	retryable := func(err error) bool {
+		return policy.retryable(err) && (fileID != "" || isRejectedError(err))
+	}
+
+	var sum *uploadChecksum
+	err := c.retryReader(ctx, op, r, retryable, func() (err error) {
+		progress.set(0)
+		sum = newUploadChecksum()
+		body := sum.reader(progress.reader(r))
+
+		var uploaded *drive.File
+		if fileID == "" {
+			uploaded, err = c.createFile(meta).
+				Media(body, options...).
+				Fields(uploadedFileFields).
+				Context(ctx).
+				Do()
+			if err == nil {
+				// 元數據已在創建時設置，重試時只覆蓋內容
+				fileID, meta = uploaded.Id, nil
+			}
+		} else {
+			uploaded, err = c.updateFile(fileID, withSHA256(meta, "")).
+				Media(body, options...).
+				Fields(uploadedFileFields).
+				Context(ctx).
+				Do()
+		}
+		if err != nil {
+			return err
+		}
+		return c.verifyUpload(progress.name, uploaded, sum)
+	})
+	if err != nil {
+		return fileID, c.config.newError(op, err)
+	}
+	progress.finish()
+
+	if err := c.saveSHA256(ctx, fileID, sum); err != nil {
+		return fileID, err
+	}
+	return fileID, nil
+}
+
+// retryReader 執行讀取 r 的上傳請求
+// r 實現 io.Seeker 時重試 retryable 返回 true 的錯誤，每次嘗試前重新定位到起始位置；
+// 否則已讀取的內容無法重發，只嘗試一次（分塊上傳時單個分塊的重試由 Drive 客戶端處理）
+func (c *Client) retryReader(ctx context.Context, op ErrorCode, r io.Reader, retryable func(error) bool, fn func() error) error {
+	seeker, ok := r.(io.Seeker)
+	if !ok {
+		return wrapAPIError(fn(), c.config.language())
+	}
+
+	start, err := seeker.Seek(0, io.SeekCurrent)
+	if err != nil {
+		// 如管道等實現了 io.Seeker 但不支持定位
+		return wrapAPIError(fn(), c.config.language())
+	}
+	return c.retryIf(ctx, op, retryable, func() error {
+		if _, err := seeker.Seek(start, io.SeekStart); err != nil {
+			return err
+		}
+		return fn()
+	})
+}